@@ -14,6 +14,14 @@ const (
 
 var globalFlags = []string{keyGlobalGitDirFlag, keyGlobalConfigFlag, keyGlobalDebugFlag}
 
+const (
+	keyRecursiveFlag = "--recursive"
+	keyIgnoreFlag    = "--ignore"
+	keyFromFlag      = "--from"
+	keyToFlag        = "--to"
+	keyBlockFlag     = "--block"
+)
+
 type GiksArgs []string
 
 func (ga GiksArgs) Binary() string {
@@ -59,6 +67,76 @@ func (ga GiksArgs) Debug() bool {
 	return true
 }
 
+// Recursive returns the directory passed via '--recursive=DIR' or '--recursive DIR' and whether the flag was set at
+// all. A flag without a directory results in an empty directory.
+func (ga GiksArgs) Recursive() (string, bool) {
+	values, ok := ga.flagValues(keyRecursiveFlag)
+	if !ok || len(values) == 0 {
+		return "", ok
+	}
+	return values[0], true
+}
+
+// Ignored returns all patterns passed via the '--ignore' flag. The flag can be repeated and every value can contain
+// a comma separated list of patterns.
+func (ga GiksArgs) Ignored() []string {
+	return ga.flagList(keyIgnoreFlag)
+}
+
+// Range returns the revisions passed via '--from' and '--to'. Absent flags result in empty revisions.
+func (ga GiksArgs) Range() (string, string) {
+	var from, to string
+	if values, _ := ga.flagValues(keyFromFlag); len(values) > 0 {
+		from = values[0]
+	}
+	if values, _ := ga.flagValues(keyToFlag); len(values) > 0 {
+		to = values[0]
+	}
+	return from, to
+}
+
+// Blocked returns all commit kinds passed via the '--block' flag. Like '--ignore' the flag can be repeated and every
+// value can contain a comma separated list.
+func (ga GiksArgs) Blocked() []string {
+	return ga.flagList(keyBlockFlag)
+}
+
+// flagList returns all comma separated values of a repeatable flag
+func (ga GiksArgs) flagList(flag string) []string {
+	var list []string
+	values, _ := ga.flagValues(flag)
+	for _, v := range values {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				list = append(list, p)
+			}
+		}
+	}
+	return list
+}
+
+// flagValues returns the values of a non-global flag which can either be passed as '--flag=value' or '--flag value'.
+func (ga GiksArgs) flagValues(flag string) ([]string, bool) {
+	var values []string
+	found := false
+	sargs := ga.sanitizeArgs()
+	for i, arg := range sargs {
+		if strings.HasPrefix(arg, flag+"=") {
+			found = true
+			values = append(values, strings.TrimPrefix(arg, flag+"="))
+			continue
+		}
+		if arg != flag {
+			continue
+		}
+		found = true
+		if i+1 < len(sargs) && !isFlag(sargs[i+1]) && !git.IsValidHook(sargs[i+1]) {
+			values = append(values, sargs[i+1])
+		}
+	}
+	return values, found
+}
+
 func (ga GiksArgs) globalFlag(flag string) (string, bool) {
 	for _, arg := range ga {
 		// flag is set in general
@@ -119,3 +119,64 @@ func TestGiksArgsGlobalFlag(t *testing.T) {
 func toArgs(s string) []string {
 	return strings.Split(s, " ")
 }
+
+func TestGiksArgsFlagValues(t *testing.T) {
+	flagTests := []struct {
+		name              string
+		input             []string
+		expectedDir       string
+		expectedRecursive bool
+		expectedIgnored   []string
+	}{
+		{
+			"separated value",
+			toArgs("giks install --recursive /src --ignore=vendor,tmp --ignore archive"),
+			"/src",
+			true,
+			[]string{"vendor", "tmp", "archive"},
+		},
+		{
+			"assigned value",
+			toArgs("giks sync --recursive=/src pre-commit"),
+			"/src",
+			true,
+			nil,
+		},
+		{
+			"flag without value",
+			toArgs("giks install --recursive pre-commit"),
+			"",
+			true,
+			nil,
+		},
+		{
+			"absent flag",
+			toArgs("giks install pre-commit"),
+			"",
+			false,
+			nil,
+		},
+	}
+	for _, tt := range flagTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ga GiksArgs = tt.input
+			dir, ok := ga.Recursive()
+			assert.Equal(t, tt.expectedDir, dir, "expected recursive directory does not match")
+			assert.Equal(t, tt.expectedRecursive, ok, "expected recursive flag presence does not match")
+			assert.Equal(t, tt.expectedIgnored, ga.Ignored(), "expected ignore patterns do not match")
+		})
+	}
+}
+
+func TestGiksArgsRange(t *testing.T) {
+	var ga GiksArgs = toArgs("giks check-messages --from=origin/main --to HEAD~1 --block fixup,wip --block squash")
+	from, to := ga.Range()
+	assert.Equal(t, "origin/main", from, "assigned revision should be returned")
+	assert.Equal(t, "HEAD~1", to, "separated revision should be returned")
+	assert.Equal(t, []string{"fixup", "wip", "squash"}, ga.Blocked(), "all blocked kinds should be returned")
+
+	ga = toArgs("giks check-messages commit-msg")
+	from, to = ga.Range()
+	assert.Empty(t, from+to, "absent flags should result in empty revisions")
+	assert.Nil(t, ga.Blocked(), "absent flag should not block anything")
+}
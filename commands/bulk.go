@@ -0,0 +1,218 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	gargs "github.com/jenpet/giks/args"
+	"github.com/jenpet/giks/cli"
+	"github.com/jenpet/giks/config"
+	"github.com/jenpet/giks/log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// ignoreFileName holds the name of the file within the root directory of a bulk operation listing ignore patterns
+const ignoreFileName = ".giksignore"
+
+const (
+	bulkStatusSuccess = "success"
+	bulkStatusSkipped = "skipped"
+	bulkStatusStale   = "stale"
+	bulkStatusError   = "error"
+)
+
+var bulkSummaryTemplateString = `
+REPOSITORY				| STATUS				| DETAILS
+{{- range .results }}
+{{ .Dir }}				| {{ .Status }}				| {{ .Details -}}
+{{ end }}
+
+SUCCESS: {{ index .counts "success" }}	SKIPPED: {{ index .counts "skipped" }}	STALE: {{ index .counts "stale" }}	ERROR: {{ index .counts "error" }}
+`
+
+var bulkSummaryTemplate *template.Template
+
+func init() {
+	var err error
+	bulkSummaryTemplate, err = template.New("bulk-summary").Parse(bulkSummaryTemplateString)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// bulkResult holds the outcome of a bulk operation for a single repository
+type bulkResult struct {
+	Dir     string
+	Status  string
+	Details string
+}
+
+// bulkOperation is performed for every repository and returns the names of stale hooks
+type bulkOperation func(cfg config.Config) ([]string, error)
+
+// ProcessRecursive runs the install or sync command for every git repository found below root. Every repository
+// is processed with its own default configuration file.
+func ProcessRecursive(gargs gargs.GiksArgs, root string) {
+	var op bulkOperation
+	switch gargs.Command() {
+	case "install":
+		op = installEnabledHooks
+	case "sync":
+		op = syncHooks
+	default:
+		log.Errorf("Command '%s' does not support the --recursive flag.", gargs.Command())
+	}
+	if root == "" {
+		root = "."
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		log.Errorf("Could not get absolute path of directory '%s'. Error: %+v", root, err)
+	}
+	ignored := append(gargs.Ignored(), readIgnoreFile(root)...)
+	repos, err := findRepositories(root, ignored)
+	if err != nil {
+		log.Errorf("Failed searching for git repositories in '%s'. Error: %+v", root, err)
+	}
+	if len(repos) == 0 {
+		log.Infof("No git repositories found in '%s'.", root)
+		return
+	}
+	verifyUserConfirmation(fmt.Sprintf("Do you want to %s the configured hooks for %d git repositories in '%s'", gargs.Command(), len(repos), root))
+	results := processRepositories(gargs, repos, op)
+	counts := map[string]int{bulkStatusSuccess: 0, bulkStatusSkipped: 0, bulkStatusStale: 0, bulkStatusError: 0}
+	for i, r := range results {
+		counts[r.Status]++
+		if rel, err := filepath.Rel(root, r.Dir); err == nil {
+			results[i].Dir = rel
+		}
+	}
+	cli.PrintTemplate(bulkSummaryTemplate, map[string]interface{}{"results": results, "counts": counts})
+}
+
+// processRepositories applies the operation to all repositories in parallel. Results are returned in the order of
+// the given repositories.
+func processRepositories(gargs gargs.GiksArgs, repos []string, op bulkOperation) []bulkResult {
+	results := make([]bulkResult, len(repos))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = processRepository(gargs, repos[i], op)
+			}
+		}()
+	}
+	for i := range repos {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+func processRepository(gargs gargs.GiksArgs, dir string, op bulkOperation) bulkResult {
+	cfg, err := config.AssembleRepositoryConfig(gargs, dir)
+	if errors.Is(err, config.ErrConfigNotFound) {
+		return bulkResult{dir, bulkStatusSkipped, "no config"}
+	}
+	if err != nil {
+		return bulkResult{dir, bulkStatusError, err.Error()}
+	}
+	stale, err := op(cfg)
+	if err != nil {
+		return bulkResult{dir, bulkStatusError, err.Error()}
+	}
+	if len(stale) > 0 {
+		return bulkResult{dir, bulkStatusStale, strings.Join(stale, ", ")}
+	}
+	return bulkResult{dir, bulkStatusSuccess, ""}
+}
+
+// installEnabledHooks installs all enabled hooks without replacing existing ones. Stale hooks are reported but left
+// untouched.
+func installEnabledHooks(cfg config.Config) ([]string, error) {
+	var stale []string
+	for _, name := range cfg.HookListNames(false) {
+		err := installHook(cfg, name, false)
+		switch {
+		case err == nil, errors.Is(err, errHookAlreadyInstalled):
+		case errors.Is(err, errHookStale):
+			stale = append(stale, name)
+		case errors.Is(err, errHookExternallyManaged):
+			log.Warnf("Hook '%s' in '%s' was not installed. Reason: %+v", name, cfg.GitDir, err)
+		default:
+			return stale, err
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// findRepositories walks the root directory and returns all directories containing a '.git' directory or file.
+// Directories matching any of the ignore patterns are skipped including their children.
+func findRepositories(root string, ignored []string) ([]string, error) {
+	var repos []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if path != root && isIgnored(root, path, ignored) {
+			log.Debugf("Skipping ignored directory '%s'", path)
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+		}
+		return nil
+	})
+	return repos, err
+}
+
+// isIgnored matches the patterns against the name of the directory as well as its path relative to the root
+func isIgnored(root string, path string, patterns []string) bool {
+	rel, _ := filepath.Rel(root, path)
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, filepath.Base(path)); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(strings.TrimSuffix(p, "/"), rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// readIgnoreFile reads the ignore patterns from the ignore file in the given directory. Empty lines and lines
+// starting with '#' are omitted.
+func readIgnoreFile(dir string) []string {
+	fh, err := os.Open(filepath.Join(dir, ignoreFileName))
+	if err != nil {
+		return nil
+	}
+	defer fh.Close()
+	var patterns []string
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	gargs "github.com/jenpet/giks/args"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const bulkTestConfig = `
+version: 1
+hooks:
+  pre-commit:
+    enabled: true
+    steps:
+      - command: echo "foo"
+`
+
+func TestProcessRepositories_shouldReportStatusPerRepository(t *testing.T) {
+	root := t.TempDir()
+	configured := gittest.NewTestRepository(filepath.Join(root, "configured"))
+	configured.WriteFile("giks.yml", bulkTestConfig)
+	unconfigured := gittest.NewTestRepository(filepath.Join(root, "unconfigured"))
+	gittest.NewTestRepository(filepath.Join(root, "vendor", "ignored"))
+	broken := gittest.NewTestRepository(filepath.Join(root, "broken"))
+	broken.WriteFile("giks.yml", "!foobar")
+
+	repos, err := findRepositories(root, []string{"vendor"})
+	assert.NoError(t, err, "finding repositories should not fail")
+	assert.Len(t, repos, 3, "ignored repositories should not be found")
+
+	var ga gargs.GiksArgs = []string{"true", "install", "--recursive", root}
+	statuses := map[string]string{}
+	for _, r := range processRepositories(ga, repos, installEnabledHooks) {
+		statuses[r.Dir] = r.Status
+	}
+	assert.Equal(t, bulkStatusSuccess, statuses[configured.AbsDir()], "configured repository should be installed")
+	assert.Equal(t, bulkStatusError, statuses[broken.AbsDir()], "broken configuration should result in an error")
+	assert.Equal(t, bulkStatusSkipped, statuses[unconfigured.AbsDir()], "repository without config should be skipped")
+	_, err = os.Stat(filepath.Join(configured.AbsGitDir(), "hooks", "pre-commit"))
+	assert.NoError(t, err, "hook file should have been written")
+
+	// alter the configuration so the installed hook becomes stale and is removed by a sync
+	configured.WriteFile("giks.yml", "version: 1\nhooks:\n  commit-msg:\n    enabled: true\n")
+	result := processRepository(ga, configured.AbsDir(), installEnabledHooks)
+	assert.Equal(t, bulkStatusSuccess, result.Status, "install should not touch hooks which are not configured")
+	result = processRepository(ga, configured.AbsDir(), syncHooks)
+	assert.Equal(t, bulkStatusStale, result.Status, "sync should report removed hooks as stale")
+	assert.Equal(t, "pre-commit", result.Details, "sync should list the stale hook")
+	_, err = os.Stat(filepath.Join(configured.AbsGitDir(), "hooks", "pre-commit"))
+	assert.True(t, os.IsNotExist(err), "stale hook should have been removed")
+	_, err = os.Stat(filepath.Join(configured.AbsGitDir(), "hooks", "commit-msg"))
+	assert.NoError(t, err, "enabled hook should have been installed")
+}
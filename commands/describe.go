@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/jenpet/giks/cli"
+	"github.com/jenpet/giks/commands/plugins"
+	"github.com/jenpet/giks/config"
+	"github.com/jenpet/giks/log"
+	"strings"
+	"text/template"
+)
+
+var pluginListTemplateString = `
+PLUGIN				| SOURCE				| DESCRIPTION
+{{- range . }}
+{{ .name }}				| {{ .source }}				| {{ .description -}}
+{{ end }}
+`
+
+var pluginDetailsTemplateString = `
+PLUGIN: {{ .name }}
+SOURCE: {{ .source }}
+DESCRIPTION: {{ .description }}
+HOOKS: {{ if .hooks }}{{ .hooks }}{{ else }}all{{ end }}
+VARS: {{ len .vars }}
+{{- range .vars }}
+  - {{ .Name }} ({{ .Type }}{{ if .Required }}, required{{ end }}{{ if .Default }}, default: '{{ .Default }}'{{ end }})
+  	{{ .Help }}
+{{- end }}
+`
+
+var pluginListTemplate *template.Template
+var pluginDetailsTemplate *template.Template
+
+func init() {
+	var err error
+	pluginListTemplate, err = template.New("plugin-list").Parse(pluginListTemplateString)
+	if err != nil {
+		panic(err)
+	}
+	pluginDetailsTemplate, err = template.New("plugin-details").Parse(pluginDetailsTemplateString)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// processPlugins handles the subcommands of the plugins command
+func processPlugins(cfg config.Config, args []string) {
+	sub := ""
+	if len(args) > 0 {
+		sub = args[0]
+	}
+	switch sub {
+	case "list", "":
+		var list []map[string]interface{}
+		for _, p := range plugins.Available(cfg.WorkingDir, cfg.PluginDirectory()) {
+			list = append(list, pluginToMap(p))
+		}
+		cli.PrintTemplate(pluginListTemplate, list)
+	case "describe":
+		if len(args) < 2 {
+			log.Error("No plugin name provided. Usage: giks plugins describe NAME")
+		}
+		p, err := plugins.Lookup(args[1], cfg.WorkingDir, cfg.PluginDirectory())
+		if err != nil {
+			log.Errorf("Could not describe plugin. Error: %+v", err)
+		}
+		cli.PrintTemplate(pluginDetailsTemplate, pluginToMap(p))
+	default:
+		fmt.Printf("Unknown plugins subcommand '%s'. Use `giks help` for more information.", sub)
+	}
+}
+
+func pluginToMap(p plugins.Plugin) map[string]interface{} {
+	m := map[string]interface{}{
+		"name":        p.ID(),
+		"source":      plugins.Source(p),
+		"description": "",
+		"hooks":       "",
+		"vars":        []plugins.VarSpec{},
+	}
+	if d, ok := p.(plugins.Describer); ok {
+		m["description"] = d.Description()
+		m["hooks"] = strings.Join(d.Hooks(), ", ")
+		m["vars"] = d.Vars()
+	}
+	return m
+}
+
+// validatePluginSteps validates the variables of all plugin steps of a hook against the schema of the plugins and
+// returns a problem for every misconfigured step
+func validatePluginSteps(cfg config.Config, h config.Hook) []string {
+	var problems []string
+	for i, step := range h.Steps {
+		if step.Plugin.Validate() != nil {
+			continue
+		}
+		if err := validatePluginStep(cfg, step.Plugin); err != nil {
+			problems = append(problems, fmt.Sprintf("step '%s/%d' is invalid: %s", h.Name, i+1, err))
+		}
+	}
+	return problems
+}
+
+// validatePluginStep validates the variables of a plugin step against the schema of the plugin
+func validatePluginStep(cfg config.Config, s config.PluginStep) error {
+	p, err := plugins.Lookup(s.Name, cfg.WorkingDir, cfg.PluginDirectory())
+	if err != nil {
+		return err
+	}
+	return plugins.ValidateVars(p, s.Vars)
+}
+
+// checkPluginSteps validates all plugin steps of a hook before any of them gets executed. Missing, unknown and invalid
+// variables fail the hook in order to not run earlier steps of a misconfigured hook.
+func checkPluginSteps(cfg config.Config, h config.Hook) error {
+	if problems := validatePluginSteps(cfg, h); len(problems) > 0 {
+		return fmt.Errorf("hook '%s' is misconfigured:\n%s", h.Name, strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// reportPluginSteps warns about misconfigured plugin steps of the hooks before they get executed
+func reportPluginSteps(cfg config.Config, hooks map[string]config.Hook) {
+	for _, h := range hooks {
+		for _, problem := range validatePluginSteps(cfg, h) {
+			log.Warn(problem)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	gargs "github.com/jenpet/giks/args"
 	"github.com/jenpet/giks/commands/plugins"
@@ -9,9 +11,10 @@ import (
 	"github.com/jenpet/giks/git"
 	"github.com/jenpet/giks/log"
 	"github.com/mattn/go-shellwords"
+	"io"
 	"os"
 	"os/exec"
-	"path"
+	"os/signal"
 	"strings"
 	"syscall"
 	"text/template"
@@ -61,13 +64,26 @@ func executeHook(cfg config.Config, gargs gargs.GiksArgs) error {
 	if !h.Enabled {
 		return fmt.Errorf("hook '%s' is not enabled", h.Name)
 	}
+	if err := checkPluginSteps(cfg, h); err != nil {
+		return err
+	}
+	// stdin can only be consumed once, hence it gets buffered in order to replay it for every step
+	stdin, updates := readHookInput(cfg, h.Name, gargs.Args(false))
+	// cancel running steps in case giks gets interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if h.CommitMessages.Enabled {
+		if err := checkCommitMessages(ctx, cfg, h.CommitMessages.MessageHook(), git.RefUpdateCommits(updates), h.CommitMessages.Block); err != nil {
+			return err
+		}
+	}
 	log.Debugf("Running hook '%s' with %d steps...", h.Name, len(h.Steps))
 	for i, step := range h.Steps {
 		// ensure that the variables are up-to-date for every step in case they changed
 		// due to previous steps
-		vars := giksVars(cfg, gargs)
+		vars := giksVars(cfg, h.Name, updates)
 		log.Debugf("Performing step '%s/%d' with variables '%s'", h.Name, i+1, strings.Join(varsToList(vars), ","))
-		if err := executeStep(cfg.WorkingDir, h, step, gargs.Args(false), vars); err != nil {
+		if err := executeStep(ctx, cfg, h, step, gargs.Args(false), vars, stepInput(stdin)); err != nil {
 			if errors.IsWarningError(err) {
 				log.Warnf("failed executing step no. %d. Error: %s", i+1, err)
 				continue
@@ -79,27 +95,68 @@ func executeHook(cfg config.Config, gargs gargs.GiksArgs) error {
 	return nil
 }
 
-func executeStep(workingDir string, h config.Hook, s config.Step, args []string, vars map[string]string) error {
+// readHookInput reads stdin for hooks which receive ref updates and parses them. The update hook receives its single
+// ref update via the arguments instead. Returned input is nil in case the hook does not receive any ref updates via
+// stdin or stdin is a terminal.
+func readHookInput(cfg config.Config, hook string, args []string) ([]byte, []git.RefUpdate) {
+	if hook == git.HookUpdate {
+		u, err := git.ParseUpdateArgs(args)
+		if err != nil {
+			log.Warnf("Failed parsing ref update of hook '%s'. Error: %+v", hook, err)
+			return nil, []git.RefUpdate{}
+		}
+		return nil, git.ResolveRefUpdates(cfg.WorkingDir, hook, []git.RefUpdate{u})
+	}
+	if !git.HasRefUpdates(hook) {
+		return nil, nil
+	}
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice != 0 {
+		log.Debugf("No input for hook '%s' available on stdin", hook)
+		return nil, []git.RefUpdate{}
+	}
+	b, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		log.Warnf("Failed reading input of hook '%s'. Error: %+v", hook, err)
+		return nil, []git.RefUpdate{}
+	}
+	updates, err := git.ParseRefUpdates(hook, string(b))
+	if err != nil {
+		log.Warnf("Failed parsing ref updates of hook '%s'. Error: %+v", hook, err)
+		return b, []git.RefUpdate{}
+	}
+	return b, git.ResolveRefUpdates(cfg.WorkingDir, hook, updates)
+}
+
+// stepInput returns a fresh reader for the buffered stdin or the actual stdin in case nothing was buffered
+func stepInput(stdin []byte) io.Reader {
+	if stdin == nil {
+		return os.Stdin
+	}
+	return bytes.NewReader(stdin)
+}
+
+func executeStep(ctx context.Context, cfg config.Config, h config.Hook, s config.Step, args []string, vars map[string]string, stdin io.Reader) error {
+	workingDir := cfg.WorkingDir
 	if s.Script != "" {
-		return executeScript(workingDir, s.Script, args, vars)
+		return executeScript(ctx, workingDir, s.Script, args, vars, stdin)
 	}
 
 	if s.Command != "" {
-		return executeCommand(workingDir, s.Command, args, vars)
+		return executeCommand(ctx, workingDir, s.Command, args, vars, stdin)
 	}
 
 	if s.Exec != "" {
-		return runExec(workingDir, s.Exec, args, vars)
+		return runExec(workingDir, s.Exec, args, vars, stdin)
 	}
 
 	if err := s.Plugin.Validate(); err == nil {
-		return executePlugin(workingDir, h.Name, s.Plugin, args, vars)
+		return executePlugin(ctx, workingDir, cfg.PluginDirectory(), h.Name, s.Plugin, args, vars, stdin)
 	}
 
 	return errors.New("step seems to be invalid")
 }
 
-func executeScript(workingDir string, path string, args []string, vars map[string]string) error {
+func executeScript(ctx context.Context, workingDir string, path string, args []string, vars map[string]string, stdin io.Reader) error {
 	log.Debugf("Executing script '%s' in directory '%s'", path, workingDir)
 	stat, err := os.Stat(path)
 	if err != nil {
@@ -113,71 +170,75 @@ func executeScript(workingDir string, path string, args []string, vars map[strin
 		args = append([]string{path}, args...)
 	}
 
-	cmd := exec.Command(bin, args...)
+	cmd := exec.CommandContext(ctx, bin, args...)
 	cmd.Env = append(os.Environ(), varsToList(vars)...)
 	cmd.Dir = workingDir
-	cmd.Stdin = os.Stdin
+	cmd.Stdin = stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
-// TODO: aside from the pre-compiled built-in plugins also support a plugin directory containing bash scripts which can
 // TODO: Allow Env variables and commands to be plugin arguments
-// be re-used to avoid copy & paste code within the config file
-func executePlugin(workingDir string, hook string, pCfg config.PluginStep, args []string, vars map[string]string) error {
+func executePlugin(ctx context.Context, workingDir string, pluginDir string, hook string, pCfg config.PluginStep, args []string, vars map[string]string, stdin io.Reader) error {
 	log.Debugf("Executing plugin '%s' in directory '%s'", pCfg.Name, workingDir)
-	p, err := plugins.Get(pCfg.Name)
+	p, err := plugins.Lookup(pCfg.Name, workingDir, pluginDir)
 	if err != nil {
 		return err
 	}
-	// merge plugin variables with vars given by giks itself and replace values of the plugin with the present value
-	// of the giks variable
-	for k, v := range pCfg.Vars {
-		// in case a value of a plugin variable is the key of a giks variable
-		// replace it accordingly
-		if val, ok := vars[strings.TrimSpace(v)]; ok {
-			vars[k] = val
-			continue
+	res := p.Run(ctx, plugins.RunInput{
+		Hook:   hook,
+		Args:   args,
+		Vars:   plugins.MergeVars(vars, pCfg.Vars),
+		Repo:   git.NewRepository(workingDir),
+		Stdin:  stdin,
+		Output: log.Writer(),
+	})
+	switch res.Status {
+	case plugins.StatusPass:
+		if pCfg.SuccessMessage != "" {
+			log.Info(pCfg.SuccessMessage)
 		}
-		vars[k] = v
+		return nil
+	case plugins.StatusSkip:
+		log.Infof("Plugin '%s' skipped. Reason: %s", pCfg.Name, res.Message)
+		return nil
+	case plugins.StatusError:
+		return fmt.Errorf("failed executing plugin '%s': %s", pCfg.Name, res)
 	}
-	exit, err := p.Run(workingDir, hook, vars, args)
-	if err != nil {
-		// error message was provided by the plugin configuration use the provided one
-		msg := pCfg.ErrorMessage
-		// default error message with error
-		if msg == "" {
-			msg = fmt.Sprintf("failed executing plugin '%s': %+v", pCfg.Name, err)
-		}
-		// return an error which forces no exit
-		if !exit {
-			return errors.NewWarningError(msg)
-		}
-		// error that forces an exit
-		return errors.New(msg)
+	// error message was provided by the plugin configuration use the provided one while keeping the findings
+	if pCfg.ErrorMessage != "" {
+		res.Message = pCfg.ErrorMessage
+	} else if res.Message != "" {
+		res.Message = fmt.Sprintf("failed executing plugin '%s': %s", pCfg.Name, res.Message)
+	} else {
+		res.Message = fmt.Sprintf("failed executing plugin '%s'", pCfg.Name)
 	}
-	if pCfg.SuccessMessage != "" {
-		log.Info(pCfg.SuccessMessage)
+	// return an error which forces no exit
+	if !res.Failed() {
+		return errors.NewWarningError(res.String())
 	}
-	return err
+	// error that forces an exit
+	return errors.New(res.String())
 }
 
-func executeCommand(workingDir string, command string, args []string, vars map[string]string) error {
+func executeCommand(ctx context.Context, workingDir string, command string, args []string, vars map[string]string, stdin io.Reader) error {
 	log.Debugf("Executing command '%s' in directory '%s'", command, workingDir)
 	args = append([]string{"-c", command}, args...)
-	cmd := exec.Command("sh", args...)
+	cmd := exec.CommandContext(ctx, "sh", args...)
 	cmd.Dir = workingDir
 	cmd.Env = append(cmd.Env, varsToList(vars)...)
-	cmd.Stdin = os.Stdin
+	cmd.Stdin = stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
-func runExec(workingDir string, line string, args []string, vars map[string]string) error {
+// runExec replaces the giks process with the executable. Input which was already read from stdin is replayed to the
+// executable by replacing stdin with an unlinked temporary file holding the input.
+func runExec(workingDir string, line string, args []string, vars map[string]string, stdin io.Reader) error {
 	log.Debugf("Running executable '%s' in directory '%s'", line, workingDir)
-	if err := os.Chdir(workingDir); err == nil {
+	if err := os.Chdir(workingDir); err != nil {
 		return fmt.Errorf("could not change into working directory '%s'. Error: %+v", workingDir, err)
 	}
 	parts, err := shellwords.Parse(line)
@@ -192,9 +253,34 @@ func runExec(workingDir string, line string, args []string, vars map[string]stri
 	}
 	env := append(os.Environ(), varsToList(vars)...)
 	args = append(parts, args...)
+	if stdin != nil && stdin != io.Reader(os.Stdin) {
+		if err := replayStdin(stdin); err != nil {
+			return fmt.Errorf("could not replay input for exec '%s'. Error: %+v", line, err)
+		}
+	}
 	return syscall.Exec(path, args, env)
 }
 
+// replayStdin writes the input into an unlinked temporary file which replaces stdin of the process. The file remains
+// available via stdin after the process got replaced.
+func replayStdin(input io.Reader) error {
+	f, err := os.CreateTemp("", "giks-stdin-")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, input); err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return redirectStdin(f)
+}
+
 func varsToList(envs map[string]string) []string {
 	var list []string
 	for k, v := range envs {
@@ -209,9 +295,15 @@ func varsToList(envs map[string]string) []string {
 	return list
 }
 
-func giksVars(cfg config.Config, gargs gargs.GiksArgs) map[string]string {
+func giksVars(cfg config.Config, hook string, updates []git.RefUpdate) map[string]string {
 	vars := map[string]string{}
-	git.ApplyMixins(path.Dir(cfg.GitDir), vars)
-	vars["GIKS_HOOK_TYPE"] = gargs.Hook()
+	// mixins describe the working tree which does not exist within bare repositories
+	if !cfg.Bare() {
+		git.ApplyMixins(cfg.WorkingDir, vars)
+	}
+	vars["GIKS_HOOK_TYPE"] = hook
+	if updates != nil {
+		git.ApplyRefUpdates(updates, vars)
+	}
 	return vars
 }
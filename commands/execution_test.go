@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	gargs "github.com/jenpet/giks/args"
+	"github.com/jenpet/giks/config"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const execStepTestDirVar = "GIKS_TEST_EXEC_STEP_DIR"
+
+func TestExecuteStep_execShouldReplayStdin(t *testing.T) {
+	// exec replaces the process, hence the step is executed within a child process running this test
+	if dir := os.Getenv(execStepTestDirVar); dir != "" {
+		cfg := config.Config{WorkingDir: dir}
+		h := config.Hook{Name: git.HookPrePush}
+		stdin, updates := readHookInput(cfg, h.Name, nil)
+		step := config.Step{Exec: "sh -c 'cat > stdin.txt'"}
+		err := executeStep(context.Background(), cfg, h, step, nil, giksVars(cfg, h.Name, updates), stepInput(stdin))
+		fmt.Fprintf(os.Stderr, "exec step returned: %+v", err)
+		os.Exit(1)
+	}
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	input := "refs/heads/main 1111111111111111111111111111111111111111 refs/heads/main 0000000000000000000000000000000000000000\n"
+	cmd := exec.Command(os.Args[0], "-test.run=^TestExecuteStep_execShouldReplayStdin$")
+	cmd.Env = append(os.Environ(), execStepTestDirVar+"="+tr.AbsDir())
+	cmd.Stdin = strings.NewReader(input)
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, "exec step should replace the process: %s", out)
+	b, _ := os.ReadFile(filepath.Join(tr.AbsDir(), "stdin.txt"))
+	assert.Equal(t, input, string(b), "buffered stdin should be replayed to the exec step")
+}
+
+func TestRunExec_shouldChangeIntoWorkingDir(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	dir := t.TempDir()
+	err := runExec(dir, "giks-absent-binary", nil, nil, nil)
+	assert.EqualError(t, err, "binary not found for exec 'giks-absent-binary'", "exec should continue after changing the directory")
+	cwd, _ := os.Getwd()
+	resolved, _ := filepath.EvalSymlinks(dir)
+	assert.Equal(t, resolved, cwd, "exec should run within the working directory")
+
+	err = runExec(filepath.Join(dir, "absent"), "giks-absent-binary", nil, nil, nil)
+	assert.Error(t, err, "exec should fail in case the working directory does not exist")
+	assert.Contains(t, err.Error(), "could not change into working directory", "error should name the working directory")
+}
+
+const upfrontValidationTestConfig = `
+version: 1
+hooks:
+  pre-commit:
+    enabled: true
+    steps:
+      - command: 'touch ran.txt'
+      - plugin:
+          name: 'string-validator'
+          vars:
+            VALIDATION_PATTERN: '.+'
+            FAIL_ON_MISMACH: 'true'
+`
+
+func TestExecuteHook_shouldValidatePluginStepsUpfront(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("giks.yml", upfrontValidationTestConfig)
+	var ga gargs.GiksArgs = []string{"true", "exec", git.HookPreCommit}
+	cfg, err := config.AssembleRepositoryConfig(ga, tr.AbsDir())
+	assert.NoError(t, err, "config should be valid")
+	err = executeHook(cfg, ga)
+	if assert.Error(t, err, "misconfigured hook should fail") {
+		assert.Contains(t, err.Error(), "step 'pre-commit/2' is invalid", "error should name the misconfigured step")
+		assert.Contains(t, err.Error(), "unknown variable 'FAIL_ON_MISMACH'", "unknown variables should be reported")
+	}
+	assert.NoFileExists(t, filepath.Join(tr.AbsDir(), "ran.txt"), "no step should run in case a later step is misconfigured")
+}
+
+func TestCheckPluginSteps(t *testing.T) {
+	cfg := config.Config{WorkingDir: t.TempDir()}
+	h := config.Hook{Name: git.HookCommitMsg, Steps: []config.Step{
+		{Command: "true"},
+		{Plugin: config.PluginStep{Name: "string-validator", Vars: map[string]string{"VALIDATION_PATTERN": ".+", "FAIL_ON_MISMACH": "true"}}},
+		{Plugin: config.PluginStep{Name: "string-validator", Vars: map[string]string{"VALIDATION_PATTERN": ".+", "FAIL_ON_MISMATCH": "yes"}}},
+	}}
+	problems := validatePluginSteps(cfg, h)
+	assert.Len(t, problems, 2, "every misconfigured step should be reported")
+	assert.Contains(t, problems[0], "step 'commit-msg/2' is invalid", "problem should name the step")
+	err := checkPluginSteps(cfg, h)
+	if assert.Error(t, err, "misconfigured steps should fail the hook") {
+		assert.Contains(t, err.Error(), "unknown variable 'FAIL_ON_MISMACH'", "unknown variables should fail the hook")
+		assert.Contains(t, err.Error(), "failed parsing 'FAIL_ON_MISMATCH' variable", "invalid values should fail the hook")
+	}
+	assert.NoError(t, checkPluginSteps(cfg, config.Hook{Name: git.HookCommitMsg, Steps: h.Steps[:1]}), "valid hook should pass")
+}
@@ -22,14 +22,36 @@ Commands:
 
 install [HOOK] Installs a given hook based on the configuration into the target directory. 
 	If no hook is provided it will install all enabled hooks of the configuration.
+	Setting 'installation.mode' to 'portable' in the configuration installs shell shims which look up giks
+	via $GIKS_BIN, the $PATH or the recorded binary and refer to the configuration relative to the repository.
+
+install --recursive DIR [--ignore=PATTERN] Installs all enabled hooks for every git repository below DIR using
+	the repository's own 'giks.yml'. Directories matching an '--ignore' pattern or a pattern listed in
+	'DIR/.giksignore' are skipped. Prints a summary of all processed repositories.
+
+sync [--recursive DIR] [--ignore=PATTERN] Installs all enabled hooks, replaces outdated hooks managed by giks and
+	removes hooks managed by giks which are no longer enabled. Supports the same flags as 'install'.
 
 uninstall [HOOK] Removes a given hook based on the configuration from the target directory. 
 	If no hook is provided all hooks will be removed.
 
 exec HOOK Executes a given hook according to the configuration provided.
+	Ref updates passed via stdin (pre-push, pre-receive, post-receive, post-rewrite) are exposed as GIKS_PUSH_*
+	variables and the original input is replayed to every step.
+
+check-messages [HOOK] [--from=REV] [--to=REV] [--block=fixup,squash,wip] Applies the plugin steps of HOOK (default:
+	commit-msg) to the message of every commit reachable from '--to' (default: HEAD) but not from '--from' and reports
+	violations per commit. '--block' additionally rejects 'fixup!'/'amend!', 'squash!' and 'WIP' commits.
+	Setting 'commit_messages.enabled' for the pre-push or pre-receive hook applies the same checks to all pushed commits.
 
 show [HOOK] [--all] Displays detailed information about the used configuration (i.e. list of hooks). 
 	If a hook is provided it will show the details for the specific hook. Adding the --all flag also lists disabled hooks.
+	Misconfigured plugin steps, e.g. unknown variables or values of the wrong type, are reported as warnings
+	and fail the hook upon execution before any of its steps runs.
+
+plugins [list] Lists all available built-in, script and external plugins.
+
+plugins describe NAME Displays the description, supported hooks and variables of a plugin.
 
 {{ if .debug }}
 Binary:		{{ .debug.binary }}
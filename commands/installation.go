@@ -16,10 +16,14 @@ import (
 // hookMask holds the access mask for installed hooks
 const hookMask = 0755
 
+// giksZoneMarker is part of every hook file written by giks
+const giksZoneMarker = "# GIKS-ZONE!"
+
 var (
 	errHookExternallyManaged = errors.New("hook is externally managed")
 	errHookAlreadyInstalled  = errors.New("hook is already installed")
 	errHookNotInstalled      = errors.New("hook is not installed")
+	errHookStale             = errors.New("hook is managed by giks but outdated, run 'giks sync' to update it")
 )
 
 var hookTemplateString = `
@@ -30,12 +34,40 @@ var hookTemplateString = `
 {{ .command }}
 `
 
+// portableHookTemplateString renders a POSIX shell shim which neither embeds the absolute path of the config file
+// nor relies on the giks binary to be located at the path recorded during the installation.
+var portableHookTemplateString = `
+#!/bin/sh
+# GIKS-ZONE!
+# This {{ .name }} hook is managed via giks (https://github.com/jenpet/giks).
+# You should not alter this file manually except you do it tenderly and know what you are actually doing.
+# To remove this hook run 'giks uninstall {{ .name }}'.
+# giks is looked up via $GIKS_BIN, the $PATH and the binary recorded during the installation (in that order).
+GIKS_REPO_ROOT="$(git rev-parse --show-toplevel 2>/dev/null || pwd)"
+if [ -n "${GIKS_BIN}" ] && command -v "${GIKS_BIN}" >/dev/null 2>&1; then
+  GIKS="${GIKS_BIN}"
+elif command -v giks >/dev/null 2>&1; then
+  GIKS="giks"
+elif [ -x {{ .binary }} ]; then
+  GIKS={{ .binary }}
+else
+{{- if .fail }}
+  echo "giks not found, failing {{ .name }} hook. Set GIKS_BIN or add giks to your PATH." >&2
+  exit 1
+{{- else }}
+  echo "giks not found, skipping {{ .name }} hook. Set GIKS_BIN or add giks to your PATH." >&2
+  exit 0
+{{- end }}
+fi
+{{ .command }}
+`
+
 func installSingleHook(cfg config.Config, h config.Hook, confirmation bool) {
 	if confirmation {
 		verifyUserConfirmation(fmt.Sprintf("Do you want to install hook '%s' for git directory '%s'", h.Name, cfg.GitDir))
 	}
 	if err := installHook(cfg, h.Name, false); err != nil {
-		if errors.Is(err, errHookAlreadyInstalled) || errors.Is(err, errHookExternallyManaged) {
+		if errors.Is(err, errHookAlreadyInstalled) || errors.Is(err, errHookExternallyManaged) || errors.Is(err, errHookStale) {
 			log.Warnf("Hook '%s' was not installed. Reason: %+v", h.Name, err)
 			return
 		}
@@ -76,9 +108,10 @@ func uninstallHookList(cfg config.Config) {
 	}
 }
 
+// installHook writes the hook file for the given hook. Forcing the installation also replaces stale hooks.
 func installHook(cfg config.Config, hookName string, force bool) error {
 	ok, err := hookIsInstalled(cfg, hookName)
-	if err != nil {
+	if err != nil && !(force && errors.Is(err, errHookStale)) {
 		return err
 	}
 	if ok && !force {
@@ -88,15 +121,16 @@ func installHook(cfg config.Config, hookName string, force bool) error {
 	content := hookFileContent(cfg, hookName)
 	err = os.WriteFile(fileName, []byte(content), hookMask)
 	if err != nil {
-		log.Errorf("failed writing hook file '%s'. Error: %+v", fileName, err)
+		return fmt.Errorf("failed writing hook file '%s'. Error: %+v", fileName, err)
 	}
 	log.Infof("Installed hook '%s' in '%s'", hookName, fileName)
 	return nil
 }
 
+// uninstallHook removes the hook file of the given hook in case it is managed by giks even if it is stale.
 func uninstallHook(cfg config.Config, hookName string) error {
 	ok, err := hookIsInstalled(cfg, hookName)
-	if err != nil {
+	if err != nil && !errors.Is(err, errHookStale) {
 		return err
 	}
 	if !ok {
@@ -104,37 +138,85 @@ func uninstallHook(cfg config.Config, hookName string) error {
 	}
 	fileName := hookFileName(cfg.GitDir, hookName)
 	if err = os.Remove(fileName); err != nil {
-		log.Errorf("failed removing hook file '%s'. Error: %+v", fileName, err)
+		return fmt.Errorf("failed removing hook file '%s'. Error: %+v", fileName, err)
 	}
 	log.Infof("Uninstalled hook '%s' by removing '%s'", hookName, fileName)
 	return nil
 }
 
+// hookIsInstalled checks whether a hook file exists for the given hook. In case it exists but does not match the
+// current configuration either errHookStale or errHookExternallyManaged is returned depending on whether the file
+// was written by giks.
 func hookIsInstalled(cfg config.Config, hookName string) (bool, error) {
 	file := hookFileName(cfg.GitDir, hookName)
 	if _, err := os.Stat(file); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return false, nil
 		}
-		log.Errorf("failed checking hook installation for hook '%s'. Error: %+v", hookName, err)
+		return false, fmt.Errorf("failed checking hook installation for hook '%s'. Error: %+v", hookName, err)
 	}
 	content := hookFileContent(cfg, hookName)
 	b, err := os.ReadFile(file)
 	if err != nil {
-		log.Errorf("could not read hook file '%s'. Error: %+v", file, err)
+		return false, fmt.Errorf("could not read hook file '%s'. Error: %+v", file, err)
 	}
-	if strings.TrimSpace(string(b)) != content {
+	if installed := strings.TrimSpace(string(b)); installed != content {
+		if strings.Contains(installed, giksZoneMarker) {
+			return true, errHookStale
+		}
 		return true, errHookExternallyManaged
 	}
 	return true, nil
 }
 
+// syncHooks aligns the installed hooks with the configuration. Enabled hooks get installed, stale hooks managed by
+// giks get replaced and hooks managed by giks which are no longer enabled get removed. Externally managed hooks
+// remain untouched. The names of all replaced or removed stale hooks are returned.
+func syncHooks(cfg config.Config) ([]string, error) {
+	var stale []string
+	enabled := cfg.HookList(false)
+	for _, hookName := range git.Hooks {
+		// hooks which can not be installed by giks can not be managed by giks either
+		if _, err := commandString(cfg.Binary, cfg.ConfigFile, hookName); err != nil {
+			continue
+		}
+		ok, err := hookIsInstalled(cfg, hookName)
+		if errors.Is(err, errHookExternallyManaged) {
+			log.Warnf("Hook '%s' was not synced. Reason: %+v", hookName, err)
+			continue
+		}
+		if err != nil && !errors.Is(err, errHookStale) {
+			return stale, err
+		}
+		_, isEnabled := enabled[hookName]
+		switch {
+		case isEnabled && (!ok || err != nil):
+			if err = installHook(cfg, hookName, true); err != nil {
+				return stale, err
+			}
+		case !isEnabled && ok:
+			if err = uninstallHook(cfg, hookName); err != nil {
+				return stale, err
+			}
+		default:
+			continue
+		}
+		if ok {
+			stale = append(stale, hookName)
+		}
+	}
+	return stale, nil
+}
+
 func hookFileName(gitDir string, hookName string) string {
 	return filepath.Join(gitDir, "hooks", hookName)
 }
 
 func hookFileContent(cfg config.Config, hookName string) string {
-	cmd, err := commandString(cfg, hookName)
+	if cfg.Installation.Portable() {
+		return portableHookFileContent(cfg, hookName)
+	}
+	cmd, err := commandString(cfg.Binary, cfg.ConfigFile, hookName)
 	if err != nil {
 		log.Errorf("could not retrieve command string for hook '%s'. Error: %+v", hookName, err)
 	}
@@ -148,16 +230,49 @@ func hookFileContent(cfg config.Config, hookName string) string {
 	return strings.TrimSpace(content.String())
 }
 
-func commandString(cfg config.Config, hookName string) (string, error) {
-	cmd := fmt.Sprintf("%s exec %s --config=%s", cfg.Binary, hookName, cfg.ConfigFile)
+func portableHookFileContent(cfg config.Config, hookName string) string {
+	cmd, err := commandString(`"${GIKS}"`, portableConfigFile(cfg), hookName)
+	if err != nil {
+		log.Errorf("could not retrieve command string for hook '%s'. Error: %+v", hookName, err)
+	}
+	var content bytes.Buffer
+	tpl, _ := template.New("portable-hook").Parse(portableHookTemplateString)
+	data := map[string]interface{}{
+		"name":    hookName,
+		"binary":  shellQuote(cfg.Binary),
+		"fail":    cfg.Installation.FailOnMissingBinary(),
+		"command": cmd,
+	}
+	_ = tpl.Execute(&content, data)
+	return strings.TrimSpace(content.String())
+}
+
+// portableConfigFile returns the quoted path of the config file relative to the root of the repository which is
+// resolved by the shim at runtime. In case the config file is located outside of the repository or no relative path
+// can be determined the absolute one is used.
+func portableConfigFile(cfg config.Config) string {
+	rel, err := filepath.Rel(cfg.WorkingDir, cfg.ConfigFile)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return shellQuote(cfg.ConfigFile)
+	}
+	return `"${GIKS_REPO_ROOT}"/` + shellQuote(filepath.ToSlash(rel))
+}
+
+// shellQuote wraps the value in single quotes so a POSIX shell takes it literally
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func commandString(binary string, configFile string, hookName string) (string, error) {
+	cmd := fmt.Sprintf("%s exec %s --config=%s", binary, hookName, configFile)
 	switch hookName {
-	case git.HookCommitMsg: // hooks with one parameter passed
+	case git.HookCommitMsg, git.HookPostRewrite: // hooks with one parameter passed
 		return addArgumentToCommand(cmd, 1), nil
 	case git.HookPrePush, git.HookPreRebase: // hooks with two parameters passed
 		return addArgumentToCommand(cmd, 2), nil
 	case git.HookPrepareCommitMsg, git.HookUpdate:
 		return addArgumentToCommand(cmd, 3), nil
-	case git.HookPreCommit, git.HookPostUpdate, git.HookPreMergeCommit, git.HookPreReceive: // hooks without any parameters passed
+	case git.HookPreCommit, git.HookPostUpdate, git.HookPreMergeCommit, git.HookPreReceive, git.HookPostReceive: // hooks without any parameters passed
 		return cmd, nil
 	}
 	return "", errors.New(fmt.Sprintf("installation with hook '%s' is not supported", hookName))
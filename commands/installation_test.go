@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"github.com/jenpet/giks/config"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testGitDir = "../test/output/git-dir"
+
+func TestInstallHook_whenPortable_shouldWriteShellShim(t *testing.T) {
+	r := gittest.NewTestRepository(testGitDir)
+	defer r.Clean()
+	cfg := config.Config{
+		ConfigFile:   filepath.Join(r.AbsDir(), "giks.yml"),
+		GitDir:       r.AbsGitDir(),
+		WorkingDir:   r.AbsDir(),
+		Binary:       "/absent/giks",
+		Installation: config.Installation{Mode: config.InstallModePortable},
+	}
+	assert.NoError(t, installHook(cfg, "commit-msg", false), "installing a portable hook should not fail")
+	b, _ := os.ReadFile(hookFileName(cfg.GitDir, "commit-msg"))
+	content := string(b)
+	assert.True(t, strings.HasPrefix(content, "#!/bin/sh"), "portable hook should be a shell script")
+	assert.NotContains(t, content, cfg.ConfigFile, "portable hook should not contain the absolute config path")
+	assert.Contains(t, content, `--config="${GIKS_REPO_ROOT}"/'giks.yml' ${1}`, "portable hook should refer to the config relatively")
+	installed, err := hookIsInstalled(cfg, "commit-msg")
+	assert.True(t, installed, "portable hook should be recognized as installed")
+	assert.NoError(t, err, "portable hook should be recognized as managed by giks")
+
+	// use a fake giks binary which prints the passed arguments
+	bin := filepath.Join(r.AbsDir(), "fake-giks")
+	_ = os.WriteFile(bin, []byte("#!/bin/sh\necho \"$@\""), 0755)
+	out, err := runHookFile(cfg, "commit-msg", "GIKS_BIN="+bin)
+	assert.NoError(t, err, "portable hook should succeed with a valid GIKS_BIN")
+	assert.Equal(t, "exec commit-msg --config="+filepath.Join(r.AbsDir(), "giks.yml")+" MSG_FILE", strings.TrimSpace(out))
+}
+
+func TestInstallHook_whenPortable_shouldQuoteRecordedPaths(t *testing.T) {
+	r := gittest.NewTestRepository(testGitDir)
+	defer r.Clean()
+	dir := filepath.Join(t.TempDir(), "it's $HOME \"`id`\"")
+	_ = os.MkdirAll(dir, 0755)
+	bin := filepath.Join(dir, "giks")
+	_ = os.WriteFile(bin, []byte("#!/bin/sh\necho \"$@\""), 0755)
+	cfg := config.Config{
+		ConfigFile:   filepath.Join(dir, "giks.yml"),
+		GitDir:       r.AbsGitDir(),
+		WorkingDir:   r.AbsDir(),
+		Binary:       bin,
+		Installation: config.Installation{Mode: config.InstallModePortable},
+	}
+	assert.NoError(t, installHook(cfg, "commit-msg", false), "installing a portable hook should not fail")
+	b, _ := os.ReadFile(hookFileName(cfg.GitDir, "commit-msg"))
+	assert.NotContains(t, string(b), "${GIKS_REPO_ROOT}\"/'..", "config outside of the repository should not be referred to relatively")
+	out, err := runHookFile(cfg, "commit-msg", "PATH=/usr/bin:/bin")
+	assert.NoError(t, err, "portable hook should run the recorded binary: %s", out)
+	assert.Equal(t, "exec commit-msg --config="+cfg.ConfigFile+" MSG_FILE", strings.TrimSpace(out))
+}
+
+func TestInstallHook_whenPortableBinaryIsMissing_shouldApplyPolicy(t *testing.T) {
+	policyTests := []struct {
+		name        string
+		policy      string
+		errExpected bool
+	}{
+		{"default skips", "", false},
+		{"skip", config.MissingBinarySkip, false},
+		{"fail", config.MissingBinaryFail, true},
+	}
+	for _, tt := range policyTests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gittest.NewTestRepository(testGitDir)
+			defer r.Clean()
+			cfg := config.Config{
+				ConfigFile:   filepath.Join(r.AbsDir(), "giks.yml"),
+				GitDir:       r.AbsGitDir(),
+				WorkingDir:   r.AbsDir(),
+				Binary:       "/absent/giks",
+				Installation: config.Installation{Mode: config.InstallModePortable, OnMissingBinary: tt.policy},
+			}
+			_ = installHook(cfg, "pre-commit", false)
+			out, err := runHookFile(cfg, "pre-commit", "PATH=/usr/bin:/bin")
+			assert.Equal(t, tt.errExpected, err != nil, "hook exit status does not match the policy")
+			assert.Contains(t, out, "giks not found", "hook should explain why giks was not executed")
+		})
+	}
+}
+
+func runHookFile(cfg config.Config, hookName string, env ...string) (string, error) {
+	cmd := exec.Command("sh", hookFileName(cfg.GitDir, hookName), "MSG_FILE")
+	cmd.Dir = cfg.WorkingDir
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
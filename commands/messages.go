@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	gargs "github.com/jenpet/giks/args"
+	"github.com/jenpet/giks/commands/plugins"
+	"github.com/jenpet/giks/config"
+	"github.com/jenpet/giks/errors"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/log"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+)
+
+// blockedCommitRegexes detect the kinds of commits which can be blocked by their subject
+var blockedCommitRegexes = map[string]*regexp.Regexp{
+	config.BlockFixup:  regexp.MustCompile(`^(fixup|amend)! `),
+	config.BlockSquash: regexp.MustCompile(`^squash! `),
+	config.BlockWIP:    regexp.MustCompile(`(?i)^\[?wip\b`),
+}
+
+// checkCommitMessageRange checks the messages of all commits within the range passed via '--from' and '--to' using
+// the steps of the passed hook which defaults to commit-msg
+func checkCommitMessageRange(cfg config.Config, gargs gargs.GiksArgs) error {
+	hook := gargs.Hook()
+	if hook == "" {
+		hook = git.HookCommitMsg
+	}
+	block := gargs.Blocked()
+	if err := config.ValidateBlockedCommits(block); err != nil {
+		return err
+	}
+	from, to := gargs.Range()
+	shas, err := commitRange(cfg.WorkingDir, from, to)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err = checkCommitMessages(ctx, cfg, hook, shas, block); err != nil {
+		return err
+	}
+	log.Infof("Messages of %d commit(s) are valid", len(shas))
+	return nil
+}
+
+// checkCommitMessages applies the message validating steps of the hook to the messages of all commits. Commits of
+// the blocked kinds are rejected regardless of the steps. Violations of all commits are collected and returned as a
+// single error which reports every violation by the SHA of the commit.
+func checkCommitMessages(ctx context.Context, cfg config.Config, hook string, shas []string, block []string) error {
+	if len(shas) == 0 {
+		return nil
+	}
+	steps, err := messageSteps(cfg, hook)
+	if err != nil {
+		return err
+	}
+	commits, err := git.NewRepository(cfg.WorkingDir).Commits(shas...)
+	if err != nil {
+		return fmt.Errorf("could not read commits: %+v", err)
+	}
+	f, err := os.CreateTemp("", "giks-commit-msg-")
+	if err != nil {
+		return fmt.Errorf("could not create commit message file: %+v", err)
+	}
+	_ = f.Close()
+	defer os.Remove(f.Name())
+
+	log.Debugf("Checking the messages of %d commit(s) with %d step(s) of hook '%s'...", len(commits), len(steps), hook)
+	vars := giksVars(cfg, git.HookCommitMsg, nil)
+	var violations []string
+	for _, c := range commits {
+		subject := c.Subject()
+		prefix := fmt.Sprintf("commit %s '%s'", c.ShortSHA(), subject)
+		for _, b := range block {
+			if blockedCommitRegexes[b].MatchString(subject) {
+				violations = append(violations, fmt.Sprintf("%s: %s commits are not allowed", prefix, b))
+			}
+		}
+		if err = os.WriteFile(f.Name(), []byte(c.Message), 0644); err != nil {
+			return fmt.Errorf("could not write commit message file: %+v", err)
+		}
+		commitVars := map[string]string{plugins.VarCommitSHA: c.SHA}
+		for k, v := range vars {
+			commitVars[k] = v
+		}
+		for _, s := range steps {
+			err := executePlugin(ctx, cfg.WorkingDir, cfg.PluginDirectory(), git.HookCommitMsg, s, []string{f.Name()}, commitVars, nil)
+			if err == nil {
+				continue
+			}
+			if errors.IsWarningError(err) {
+				log.Warnf("%s: %s", prefix, err)
+				continue
+			}
+			violations = append(violations, fmt.Sprintf("%s: %s", prefix, err))
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d commit message violation(s):\n%s", len(violations), strings.Join(violations, "\n"))
+	}
+	return nil
+}
+
+// messageSteps returns the plugin steps of the hook which validate commit messages. Plugins which declare their
+// supported hooks without the commit-msg hook as well as commands and scripts are left out.
+func messageSteps(cfg config.Config, hook string) ([]config.PluginStep, error) {
+	h, err := cfg.LookupHook(hook)
+	if err != nil {
+		return nil, fmt.Errorf("could not find hook '%s' providing the message validating steps: %s", hook, err)
+	}
+	if err = checkPluginSteps(cfg, *h); err != nil {
+		return nil, err
+	}
+	var steps []config.PluginStep
+	for i, s := range h.Steps {
+		if s.Plugin.Validate() != nil {
+			log.Debugf("Skipping step '%s/%d' since only plugins can validate commit messages", hook, i+1)
+			continue
+		}
+		p, _ := plugins.Lookup(s.Plugin.Name, cfg.WorkingDir, cfg.PluginDirectory())
+		if !plugins.SupportsHook(p, git.HookCommitMsg) {
+			log.Debugf("Skipping step '%s/%d' since plugin '%s' does not support hook '%s'", hook, i+1, p.ID(), git.HookCommitMsg)
+			continue
+		}
+		steps = append(steps, s.Plugin)
+	}
+	return steps, nil
+}
+
+// commitRange returns the commits reachable from 'to' but not from 'from' starting with the oldest one. 'to'
+// defaults to HEAD and all commits reachable from 'to' are returned in case 'from' is empty.
+func commitRange(workingDir string, from string, to string) ([]string, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	rev := to
+	if from != "" {
+		rev = from + ".." + to
+	}
+	out, err := git.NewRepository(workingDir).Command("rev-list", "--reverse", rev)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the commits of range '%s': %+v", rev, err)
+	}
+	return strings.Fields(out), nil
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	gargs "github.com/jenpet/giks/args"
+	"github.com/jenpet/giks/config"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const messagesTestConfig = `
+version: 1
+hooks:
+  commit-msg:
+    enabled: true
+    steps:
+      - command: 'exit 1'
+      - plugin:
+          name: 'conventional-commits'
+      - plugin:
+          name: 'dco'
+      - plugin:
+          name: 'blob-size'
+  pre-push:
+    enabled: true
+    commit_messages:
+      enabled: true
+      block: ['fixup', 'wip']
+`
+
+func TestCheckCommitMessages(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("giks.yml", messagesTestConfig)
+	commit := func(msg string) string {
+		tr.WriteFile("file.txt", msg)
+		tr.AddAll()
+		tr.Commit(msg)
+		sha, _ := tr.Command("rev-parse", "HEAD")
+		return strings.TrimSpace(sha)
+	}
+	base := commit("feat: base\n\nSigned-off-by: giks <giks@example.com>")
+	valid := commit("fix: valid\n\nSigned-off-by: giks <giks@example.com>")
+	unsigned := commit("fix: unsigned")
+	wip := commit("WIP: not conventional\n\nSigned-off-by: giks <giks@example.com>")
+	fixup := commit("fixup! fix: valid\n\nSigned-off-by: giks <giks@example.com>")
+
+	var ga gargs.GiksArgs = []string{"true", "check-messages"}
+	cfg, err := config.AssembleRepositoryConfig(ga, tr.AbsDir())
+	assert.NoError(t, err, "config should be valid")
+
+	shas, err := commitRange(cfg.WorkingDir, base, "")
+	assert.NoError(t, err, "range should be resolved")
+	assert.Equal(t, []string{valid, unsigned, wip, fixup}, shas, "commits of the range should be returned starting with the oldest one")
+
+	err = checkCommitMessages(context.Background(), cfg, git.HookCommitMsg, shas, nil)
+	if assert.Error(t, err, "invalid messages should be reported") {
+		assert.Contains(t, err.Error(), "commit "+unsigned[:7]+" 'fix: unsigned': failed executing plugin 'dco'", "missing sign-off should be reported by commit")
+		assert.Contains(t, err.Error(), "commit "+wip[:7]+" 'WIP: not conventional': failed executing plugin 'conventional-commits'", "invalid message should be reported by commit")
+		assert.NotContains(t, err.Error(), valid[:7], "valid commit should not be reported")
+		assert.NotContains(t, err.Error(), "blob-size", "plugins not supporting the commit-msg hook should be skipped")
+	}
+
+	err = checkCommitMessages(context.Background(), cfg, git.HookCommitMsg, []string{wip, fixup}, cfg.Hook(git.HookPrePush).CommitMessages.Block)
+	if assert.Error(t, err, "blocked commits should be reported") {
+		assert.Contains(t, err.Error(), "commit "+wip[:7]+" 'WIP: not conventional': wip commits are not allowed", "WIP commit should be blocked")
+		assert.Contains(t, err.Error(), "commit "+fixup[:7]+" 'fixup! fix: valid': fixup commits are not allowed", "fixup commit should be blocked")
+	}
+
+	assert.NoError(t, checkCommitMessages(context.Background(), cfg, git.HookCommitMsg, []string{valid}, nil), "valid commit should pass")
+	assert.Error(t, checkCommitMessages(context.Background(), cfg, git.HookPreCommit, []string{valid}, nil), "unknown message hook should be reported")
+}
@@ -0,0 +1,230 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	varBlobSizeMax         = "BLOB_SIZE_MAX"
+	varBlobSizeOverrides   = "BLOB_SIZE_OVERRIDES"
+	varBlobSizeFailOnMatch = "BLOB_SIZE_FAIL_ON_MATCH"
+)
+
+const defaultBlobSizeMax = "5MB"
+
+// sizeUnits maps the supported unit suffixes to their factor in bytes
+var sizeUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"GIB", 1 << 30}, {"GB", 1 << 30}, {"G", 1 << 30},
+	{"MIB", 1 << 20}, {"MB", 1 << 20}, {"M", 1 << 20},
+	{"KIB", 1 << 10}, {"KB", 1 << 10}, {"K", 1 << 10},
+	{"B", 1},
+}
+
+// BlobSize guards the repository against large files by inspecting the size of the staged or pushed blobs
+type BlobSize struct{}
+
+// sizeOverride is a maximum size for all files matching the glob. A negative size disables the limit.
+type sizeOverride struct {
+	glob string
+	max  int64
+}
+
+func (bs BlobSize) ID() string {
+	return "blob-size"
+}
+
+func (bs BlobSize) Description() string {
+	return "Blocks staged or pushed files exceeding a maximum size and points at files which should be tracked by Git LFS."
+}
+
+func (bs BlobSize) Hooks() []string {
+	return []string{git.HookPreCommit, git.HookPrePush}
+}
+
+func (bs BlobSize) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varBlobSizeMax, Type: VarTypeString, Default: defaultBlobSizeMax, Help: "maximum size of a file, e.g. '500KB', '5MB' or '1GB'"},
+		{Name: varBlobSizeOverrides, Type: VarTypeString,
+			Help: "newline separated list of 'glob=size' entries overriding the maximum size. The first matching glob wins, 'unlimited' disables the limit"},
+		{Name: varBlobSizeFailOnMatch, Type: VarTypeBool, Default: "true", Help: "fail instead of warn in case a file is too large"},
+	}
+}
+
+func (bs BlobSize) Run(ctx context.Context, in RunInput) Result {
+	if !contains(bs.Hooks(), in.Hook) {
+		return hookUnsupported(in.Hook, bs.ID())
+	}
+	maxSize := in.Vars[varBlobSizeMax]
+	if strings.TrimSpace(maxSize) == "" {
+		maxSize = defaultBlobSizeMax
+	}
+	max, err := parseSize(maxSize)
+	if err != nil {
+		return Errorf("variable '%s' is invalid: %s", varBlobSizeMax, err)
+	}
+	overrides, err := sizeOverrides(in.Vars[varBlobSizeOverrides])
+	if err != nil {
+		return Errorf("variable '%s' is invalid: %s", varBlobSizeOverrides, err)
+	}
+	failOnMatch := true
+	if _, ok := in.Vars[varBlobSizeFailOnMatch]; ok {
+		if failOnMatch, err = in.Vars.Bool(varBlobSizeFailOnMatch, false); err != nil {
+			return Errorf("%s", err)
+		}
+	}
+
+	blobs, commits, err := changedBlobs(in)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	shas := make([]string, len(blobs))
+	for i, b := range blobs {
+		shas[i] = b.SHA
+	}
+	infos, err := in.Repo.ObjectInfos(shas...)
+	if err != nil {
+		return Errorf("could not determine blob sizes: %+v", err)
+	}
+
+	var large []int
+	var paths []string
+	for i, info := range infos {
+		limit := maxSizeFor(blobs[i].Path, max, overrides)
+		if !info.Missing && limit >= 0 && info.Size > limit {
+			large = append(large, i)
+			paths = git.AppendUnique(paths, blobs[i].Path)
+		}
+	}
+	if len(large) == 0 {
+		return Pass()
+	}
+	filters, err := in.Repo.Attribute("filter", paths...)
+	if err != nil {
+		return Errorf("could not read git attributes: %+v", err)
+	}
+
+	var findings []Finding
+	for _, i := range large {
+		b := blobs[i]
+		msg := fmt.Sprintf("file has %s which exceeds the maximum of %s", formatSize(infos[i].Size), formatSize(maxSizeFor(b.Path, max, overrides)))
+		if commits[i] != "" {
+			msg = fmt.Sprintf("%s in commit %.7s", msg, commits[i])
+		}
+		if filters[b.Path] == "lfs" {
+			msg += ". The file is tracked by Git LFS according to .gitattributes but was added without it, run 'git lfs install' and add it again"
+		} else {
+			pattern := b.Path
+			if ext := path.Ext(b.Path); ext != "" {
+				pattern = "*" + ext
+			}
+			msg += fmt.Sprintf(". Consider tracking it with Git LFS: git lfs track '%s'", pattern)
+		}
+		findings = append(findings, Finding{File: b.Path, Message: msg})
+	}
+	return Fail(failOnMatch, fmt.Sprintf("found %d file(s) exceeding the maximum size", len(findings)), findings...)
+}
+
+// changedBlobs returns the staged blobs or the blobs of all pushed commits alongside the commit they belong to
+func changedBlobs(in RunInput) ([]git.ChangedBlob, []string, error) {
+	if in.Hook == git.HookPreCommit {
+		blobs, err := in.Repo.StagedBlobs()
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not determine staged files: %+v", err)
+		}
+		return blobs, make([]string, len(blobs)), nil
+	}
+	pushed, err := pushedCommits(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	var blobs []git.ChangedBlob
+	var commits []string
+	for _, c := range pushed {
+		cb, err := in.Repo.CommitBlobs(c)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not determine files of commit '%s': %+v", c, err)
+		}
+		for _, b := range cb {
+			blobs = append(blobs, b)
+			commits = append(commits, c)
+		}
+	}
+	return blobs, commits, nil
+}
+
+// sizeOverrides parses the 'glob=size' entries
+func sizeOverrides(list string) ([]sizeOverride, error) {
+	var overrides []sizeOverride
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		i := strings.LastIndex(line, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("override '%s' is malformed, expected 'glob=size'", line)
+		}
+		glob, size := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("provided glob '%s' is malformed", glob)
+		}
+		o := sizeOverride{glob: glob, max: -1}
+		if !strings.EqualFold(size, "unlimited") {
+			max, err := parseSize(size)
+			if err != nil {
+				return nil, err
+			}
+			o.max = max
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
+
+// maxSizeFor returns the maximum size of the first matching override or the default maximum
+func maxSizeFor(file string, max int64, overrides []sizeOverride) int64 {
+	for _, o := range overrides {
+		if matchGlob(o.glob, file) {
+			return o.max
+		}
+	}
+	return max
+}
+
+// parseSize parses sizes like '512', '100KB' or '1.5MB'. Units are binary, i.e. 1KB equals 1024 bytes.
+func parseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	factor := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, factor = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("size '%s' is malformed", size)
+	}
+	return int64(n * float64(factor)), nil
+}
+
+// formatSize formats the size using the largest fitting unit
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1fGB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", size)
+}
@@ -0,0 +1,84 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBlobSizeStaged(t *testing.T) {
+	blobSizeTests := []struct {
+		name           string
+		vars           map[string]string
+		statusExpected Status
+		filesExpected  []string
+	}{
+		{"default maximum", map[string]string{}, StatusPass, nil},
+		{"maximum exceeded", map[string]string{"BLOB_SIZE_MAX": "1KB"}, StatusFail, []string{"assets/logo.png", "data.bin"}},
+		{"override", map[string]string{"BLOB_SIZE_MAX": "1KB", "BLOB_SIZE_OVERRIDES": "*.png=unlimited\nassets/*=1B"},
+			StatusFail, []string{"data.bin"}},
+		{"warning", map[string]string{"BLOB_SIZE_MAX": "1.5K", "BLOB_SIZE_FAIL_ON_MATCH": "false"}, StatusWarn, []string{"data.bin"}},
+		{"invalid size", map[string]string{"BLOB_SIZE_MAX": "big"}, StatusError, nil},
+	}
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile(".gitattributes", "*.bin filter=lfs\n")
+	tr.WriteFile("small.txt", "small")
+	tr.WriteFile("assets/logo.png", strings.Repeat("p", 1500))
+	tr.WriteFile("data.bin", strings.Repeat("b", 2048))
+	tr.AddAll()
+
+	bs, _ := Get("blob-size")
+	for _, tt := range blobSizeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := testInput(git.HookPreCommit, tt.vars, nil)
+			in.Repo = git.NewRepository(tr.AbsDir())
+			res := bs.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match result: %s", res)
+			var files []string
+			for _, f := range res.Findings {
+				files = append(files, f.File)
+				if f.File == "data.bin" {
+					assert.Contains(t, f.Message, "tracked by Git LFS according to .gitattributes", "LFS tracked files should be pointed out")
+				}
+			}
+			assert.ElementsMatch(t, tt.filesExpected, files, "expected files should be reported")
+		})
+	}
+}
+
+func TestBlobSizePushed(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("a.txt", "a")
+	tr.AddAll()
+	tr.Commit("initial")
+	tr.WriteFile("large.txt", strings.Repeat("l", 4096))
+	tr.AddAll()
+	tr.Commit("add large file")
+	_, _ = tr.Command("rm", "large.txt")
+	tr.Commit("remove large file")
+	head, _ := tr.Command("rev-parse", "HEAD")
+
+	in := testInput(git.HookPrePush, map[string]string{"BLOB_SIZE_MAX": "1KB"}, nil)
+	in.Repo = git.NewRepository(tr.AbsDir())
+	in.Stdin = strings.NewReader("refs/heads/main " + strings.TrimSpace(head) + " refs/heads/main " + zeroSHA + "\n")
+	bs, _ := Get("blob-size")
+	res := bs.Run(context.Background(), in)
+	assert.Equal(t, StatusFail, res.Status, "large file within a pushed commit should be found: %s", res)
+	assert.Len(t, res.Findings, 1, "large file should be reported once")
+	assert.Contains(t, res.Findings[0].Message, "git lfs track '*.txt'", "LFS should be suggested")
+}
+
+func TestParseSize(t *testing.T) {
+	sizes := map[string]int64{"512": 512, "1KB": 1024, "1.5mb": 1572864, "2GiB": 2 << 30, "10 B": 10}
+	for in, expected := range sizes {
+		size, err := parseSize(in)
+		assert.NoError(t, err, "size '%s' should be parsed", in)
+		assert.Equal(t, expected, size, "size '%s' should be parsed", in)
+	}
+	_, err := parseSize("-1MB")
+	assert.Error(t, err, "negative sizes should be rejected")
+}
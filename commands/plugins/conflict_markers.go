@@ -0,0 +1,98 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"strings"
+)
+
+const (
+	varConflictExcludes    = "CONFLICT_MARKERS_EXCLUDES"
+	varConflictFailOnMatch = "CONFLICT_MARKERS_FAIL_ON_MATCH"
+)
+
+// conflictMarkerLength is the length of the markers git writes into conflicting files
+const conflictMarkerLength = 7
+
+// ConflictMarkers detects unresolved merge conflicts within the staged files
+type ConflictMarkers struct{}
+
+func (cm ConflictMarkers) ID() string {
+	return "conflict-markers"
+}
+
+func (cm ConflictMarkers) Description() string {
+	return "Detects merge conflict markers within the staged content of text files."
+}
+
+func (cm ConflictMarkers) Hooks() []string {
+	return []string{git.HookPreCommit, git.HookPreMergeCommit}
+}
+
+func (cm ConflictMarkers) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varConflictExcludes, Type: VarTypeList,
+			Help: "space separated list of globs of files which are not scanned, e.g. '*.md testdata/'"},
+		{Name: varConflictFailOnMatch, Type: VarTypeBool, Default: "true", Help: "fail instead of warn in case conflict markers were found"},
+	}
+}
+
+func (cm ConflictMarkers) Run(ctx context.Context, in RunInput) Result {
+	if !contains(cm.Hooks(), in.Hook) {
+		return hookUnsupported(in.Hook, cm.ID())
+	}
+	failOnMatch := true
+	if _, ok := in.Vars[varConflictFailOnMatch]; ok {
+		var err error
+		if failOnMatch, err = in.Vars.Bool(varConflictFailOnMatch, false); err != nil {
+			return Errorf("%s", err)
+		}
+	}
+	blobs, contents, err := stagedContents(in, in.Vars.List(varConflictExcludes))
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	var findings []Finding
+	for i, b := range blobs {
+		if isBinary(contents[i]) {
+			continue
+		}
+		findings = append(findings, conflictMarkers(b.Path, contents[i])...)
+	}
+	if len(findings) > 0 {
+		return Fail(failOnMatch, "staged files contain merge conflict markers", findings...)
+	}
+	return Pass()
+}
+
+// conflictMarkers returns a finding for every marker line. Since '=======' is also used to underline headings it is
+// only reported within a conflict which was opened by '<<<<<<<'.
+func conflictMarkers(file string, content string) []Finding {
+	var findings []Finding
+	inConflict := false
+	for n, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		marker := ""
+		switch {
+		case isConflictMarker(line, '<'):
+			marker, inConflict = "<<<<<<<", true
+		case isConflictMarker(line, '|') && inConflict:
+			marker = "|||||||"
+		case line == strings.Repeat("=", conflictMarkerLength) && inConflict:
+			marker = "======="
+		case isConflictMarker(line, '>'):
+			marker, inConflict = ">>>>>>>", false
+		}
+		if marker != "" {
+			findings = append(findings, Finding{File: file, Line: n + 1, Column: 1, Message: fmt.Sprintf("merge conflict marker '%s'", marker)})
+		}
+	}
+	return findings
+}
+
+// isConflictMarker returns whether the line consists of the marker optionally followed by a space and a label
+func isConflictMarker(line string, c byte) bool {
+	marker := strings.Repeat(string(c), conflictMarkerLength)
+	return line == marker || strings.HasPrefix(line, marker+" ")
+}
@@ -0,0 +1,59 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConflict = `package main
+
+<<<<<<< HEAD
+var a = 1
+||||||| base
+var a = 0
+=======
+var a = 2
+>>>>>>> feature
+`
+
+func TestConflictMarkers(t *testing.T) {
+	conflictTests := []struct {
+		name             string
+		vars             map[string]string
+		statusExpected   Status
+		findingsExpected []string
+	}{
+		{"conflicts are reported", map[string]string{}, StatusFail,
+			[]string{"main.go:3:1", "main.go:5:1", "main.go:7:1", "main.go:9:1", "testdata/conflict.txt:1:1"}},
+		{"excluded files", map[string]string{"CONFLICT_MARKERS_EXCLUDES": "testdata/ *.md", "CONFLICT_MARKERS_FAIL_ON_MATCH": "false"}, StatusWarn,
+			[]string{"main.go:3:1", "main.go:5:1", "main.go:7:1", "main.go:9:1"}},
+	}
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("main.go", testConflict)
+	tr.WriteFile("README.md", "Title\n=======\n\n<<<<<<<<<< not a marker\n")
+	tr.WriteFile("testdata/conflict.txt", "<<<<<<< ours\n")
+	tr.WriteFile("image.bin", "\x00"+testConflict)
+	tr.AddAll()
+	// the working tree is not scanned
+	tr.WriteFile("main.go", "package main\n")
+
+	cm, _ := Get("conflict-markers")
+	for _, tt := range conflictTests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := testInput(git.HookPreCommit, tt.vars, nil)
+			in.Repo = git.NewRepository(tr.AbsDir())
+			res := cm.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match result: %s", res)
+			var locations []string
+			for _, f := range res.Findings {
+				locations = append(locations, strings.SplitN(f.String(), ": ", 2)[0])
+			}
+			assert.ElementsMatch(t, tt.findingsExpected, locations, "expected markers should be reported")
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	varConventionalTypes           = "CONVENTIONAL_COMMITS_TYPES"
+	varConventionalScopes          = "CONVENTIONAL_COMMITS_SCOPES"
+	varConventionalRequireScope    = "CONVENTIONAL_COMMITS_REQUIRE_SCOPE"
+	varConventionalMaxHeaderLength = "CONVENTIONAL_COMMITS_MAX_HEADER_LENGTH"
+	varConventionalBreakingFooter  = "CONVENTIONAL_COMMITS_REQUIRE_BREAKING_FOOTER"
+	varConventionalFailOnMismatch  = "CONVENTIONAL_COMMITS_FAIL_ON_MISMATCH"
+)
+
+const (
+	defaultConventionalTypes           = "feat fix docs style refactor perf test build ci chore revert"
+	defaultConventionalMaxHeaderLength = 72
+)
+
+// scissorsLine marks the start of the diff git appends to the message in case 'commit --verbose' is used
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+var conventionalFooterRegexp = regexp.MustCompile(`^(BREAKING CHANGE|BREAKING-CHANGE|[A-Za-z0-9-]+)(: | #)(.*)$`)
+
+// ConventionalCommits validates commit messages against the Conventional Commits specification
+// (https://www.conventionalcommits.org).
+type ConventionalCommits struct{}
+
+// conventionalCommit is a parsed commit message
+type conventionalCommit struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+	Body        string
+	Footers     []conventionalFooter
+}
+
+type conventionalFooter struct {
+	Token string
+	Value string
+}
+
+func (cc ConventionalCommits) ID() string {
+	return "conventional-commits"
+}
+
+func (cc ConventionalCommits) Description() string {
+	return "Validates the commit message against the Conventional Commits specification."
+}
+
+func (cc ConventionalCommits) Hooks() []string {
+	return []string{git.HookCommitMsg}
+}
+
+func (cc ConventionalCommits) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varConventionalTypes, Type: VarTypeList, Default: defaultConventionalTypes, Help: "space separated list of allowed types"},
+		{Name: varConventionalScopes, Type: VarTypeList, Help: "space separated list of allowed scopes, any scope is allowed if empty"},
+		{Name: varConventionalRequireScope, Type: VarTypeBool, Default: "false", Help: "require every header to contain a scope"},
+		{Name: varConventionalMaxHeaderLength, Type: VarTypeInt, Default: fmt.Sprint(defaultConventionalMaxHeaderLength), Help: "maximum length of the header, 0 disables the check"},
+		{Name: varConventionalBreakingFooter, Type: VarTypeBool, Default: "false", Help: "require a 'BREAKING CHANGE' footer for headers marked with '!'"},
+		{Name: varConventionalFailOnMismatch, Type: VarTypeBool, Default: "true", Help: "fail instead of warn in case the message is invalid"},
+	}
+}
+
+func (cc ConventionalCommits) Run(ctx context.Context, in RunInput) Result {
+	if in.Hook != git.HookCommitMsg {
+		return hookUnsupported(in.Hook, cc.ID())
+	}
+	if len(in.Args) == 0 {
+		return Errorf("no commit message file provided")
+	}
+	rules, err := conventionalRulesFromVars(in.Vars)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	b, err := os.ReadFile(in.Args[0])
+	if err != nil {
+		return Errorf("could not read commit message file: %+v", err)
+	}
+	if findings := rules.validate(string(b)); len(findings) > 0 {
+		// findings refer to the cleaned message, hence their lines are mapped back to the lines of the file
+		_, rawLines := cleanCommitMessageLines(string(b))
+		for i := range findings {
+			findings[i].File = in.Args[0]
+			if l := findings[i].Line; l > 0 && l <= len(rawLines) {
+				findings[i].Line = rawLines[l-1]
+			}
+		}
+		return Fail(rules.failOnMismatch, "commit message does not follow the Conventional Commits specification", findings...)
+	}
+	return Pass()
+}
+
+// conventionalRules holds the configured validation rules
+type conventionalRules struct {
+	types           []string
+	scopes          []string
+	requireScope    bool
+	maxHeaderLength int
+	breakingFooter  bool
+	failOnMismatch  bool
+}
+
+func conventionalRulesFromVars(vars Vars) (conventionalRules, error) {
+	r := conventionalRules{
+		types:           vars.List(varConventionalTypes),
+		scopes:          vars.List(varConventionalScopes),
+		maxHeaderLength: defaultConventionalMaxHeaderLength,
+		failOnMismatch:  true,
+	}
+	if len(r.types) == 0 {
+		r.types = strings.Fields(defaultConventionalTypes)
+	}
+	var err error
+	if r.requireScope, err = vars.Bool(varConventionalRequireScope, false); err != nil {
+		return r, err
+	}
+	if _, ok := vars[varConventionalMaxHeaderLength]; ok {
+		if r.maxHeaderLength, err = vars.Int(varConventionalMaxHeaderLength, false); err != nil {
+			return r, err
+		}
+	}
+	if r.breakingFooter, err = vars.Bool(varConventionalBreakingFooter, false); err != nil {
+		return r, err
+	}
+	if _, ok := vars[varConventionalFailOnMismatch]; ok {
+		if r.failOnMismatch, err = vars.Bool(varConventionalFailOnMismatch, false); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// validate parses the message and returns a finding for every violated rule
+func (r conventionalRules) validate(msg string) []Finding {
+	c, findings := parseConventionalCommit(msg)
+	if len(findings) > 0 {
+		return findings
+	}
+	header := strings.SplitN(cleanCommitMessage(msg), "\n", 2)[0]
+	if !contains(r.types, c.Type) {
+		findings = append(findings, Finding{Line: 1, Column: 1,
+			Message: fmt.Sprintf("type '%s' is not allowed, use one of: %s", c.Type, strings.Join(r.types, ", "))})
+	}
+	scopeColumn := len(c.Type) + 2
+	if c.Scope == "" && r.requireScope {
+		findings = append(findings, Finding{Line: 1, Column: scopeColumn - 1, Message: "scope is required, e.g. 'feat(parser): ...'"})
+	}
+	if c.Scope != "" && len(r.scopes) > 0 && !contains(r.scopes, c.Scope) {
+		findings = append(findings, Finding{Line: 1, Column: scopeColumn,
+			Message: fmt.Sprintf("scope '%s' is not allowed, use one of: %s", c.Scope, strings.Join(r.scopes, ", "))})
+	}
+	if r.maxHeaderLength > 0 && len([]rune(header)) > r.maxHeaderLength {
+		findings = append(findings, Finding{Line: 1, Column: r.maxHeaderLength + 1,
+			Message: fmt.Sprintf("header is %d characters long, the maximum is %d", len([]rune(header)), r.maxHeaderLength)})
+	}
+	if c.Breaking && r.breakingFooter && !c.hasBreakingFooter() {
+		findings = append(findings, Finding{Line: 1, Column: strings.Index(header, "!") + 1,
+			Message: "breaking change marker '!' requires a 'BREAKING CHANGE: <description>' footer"})
+	}
+	return findings
+}
+
+// parseConventionalCommit parses the message into its parts. Findings are returned in case the message is
+// structurally invalid.
+func parseConventionalCommit(msg string) (conventionalCommit, []Finding) {
+	var c conventionalCommit
+	lines := strings.Split(cleanCommitMessage(msg), "\n")
+	header := lines[0]
+	if strings.TrimSpace(header) == "" {
+		return c, []Finding{{Line: 1, Column: 1, Message: "commit message is empty"}}
+	}
+
+	// type
+	pos := 0
+	for pos < len(header) && isTypeChar(header[pos]) {
+		pos++
+	}
+	if pos == 0 {
+		return c, []Finding{{Line: 1, Column: 1, Message: "header has to start with a type, e.g. 'feat: <description>'"}}
+	}
+	c.Type = header[:pos]
+
+	// optional scope
+	if pos < len(header) && header[pos] == '(' {
+		end := strings.IndexByte(header[pos:], ')')
+		if end < 0 {
+			return c, []Finding{{Line: 1, Column: pos + 1, Message: "scope is not closed, expected ')'"}}
+		}
+		c.Scope = header[pos+1 : pos+end]
+		if strings.TrimSpace(c.Scope) == "" {
+			return c, []Finding{{Line: 1, Column: pos + 2, Message: "scope must not be empty"}}
+		}
+		pos += end + 1
+	}
+
+	// optional breaking change marker
+	if pos < len(header) && header[pos] == '!' {
+		c.Breaking = true
+		pos++
+	}
+
+	if !strings.HasPrefix(header[pos:], ": ") {
+		return c, []Finding{{Line: 1, Column: pos + 1, Message: "type and scope have to be followed by ': ', e.g. 'feat(parser): <description>'"}}
+	}
+	pos += 2
+	c.Description = strings.TrimSpace(header[pos:])
+	if c.Description == "" {
+		return c, []Finding{{Line: 1, Column: pos + 1, Message: "description must not be empty"}}
+	}
+
+	if len(lines) == 1 {
+		return c, nil
+	}
+	if strings.TrimSpace(lines[1]) != "" {
+		return c, []Finding{{Line: 2, Column: 1, Message: "header has to be followed by a blank line"}}
+	}
+	c.Body, c.Footers = splitBodyAndFooters(lines[2:])
+	for _, f := range c.Footers {
+		if f.Token == "BREAKING CHANGE" || f.Token == "BREAKING-CHANGE" {
+			c.Breaking = true
+		}
+	}
+	return c, nil
+}
+
+// splitBodyAndFooters treats the last paragraph as footers in case it starts with a footer token
+func splitBodyAndFooters(lines []string) (string, []conventionalFooter) {
+	start := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			break
+		}
+		start = i
+	}
+	if start == len(lines) || !conventionalFooterRegexp.MatchString(lines[start]) {
+		return strings.TrimSpace(strings.Join(lines, "\n")), nil
+	}
+	var footers []conventionalFooter
+	for _, line := range lines[start:] {
+		if m := conventionalFooterRegexp.FindStringSubmatch(line); m != nil {
+			footers = append(footers, conventionalFooter{Token: m[1], Value: m[3]})
+			continue
+		}
+		// continuation of the previous footer value
+		footers[len(footers)-1].Value += "\n" + line
+	}
+	return strings.TrimSpace(strings.Join(lines[:start], "\n")), footers
+}
+
+func (c conventionalCommit) hasBreakingFooter() bool {
+	for _, f := range c.Footers {
+		if (f.Token == "BREAKING CHANGE" || f.Token == "BREAKING-CHANGE") && strings.TrimSpace(f.Value) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanCommitMessage removes comments and everything below the scissors line like git does when committing
+func cleanCommitMessage(msg string) string {
+	lines, _ := cleanCommitMessageLines(msg)
+	return strings.Join(lines, "\n")
+}
+
+// cleanCommitMessageLines returns the lines of the cleaned message along with their 1-based line numbers within the
+// raw message
+func cleanCommitMessageLines(msg string) ([]string, []int) {
+	var lines []string
+	var numbers []int
+	for i, line := range strings.Split(strings.ReplaceAll(msg, "\r\n", "\n"), "\n") {
+		if line == scissorsLine {
+			break
+		}
+		line = strings.TrimRight(line, " \t")
+		// comments and leading blank lines are dropped
+		if strings.HasPrefix(line, "#") || line == "" && len(lines) == 0 {
+			continue
+		}
+		lines = append(lines, line)
+		numbers = append(numbers, i+1)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines, numbers = lines[:len(lines)-1], numbers[:len(numbers)-1]
+	}
+	return lines, numbers
+}
+
+func isTypeChar(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
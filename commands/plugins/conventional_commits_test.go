@@ -0,0 +1,134 @@
+package plugins
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	msg := "feat(parser)!: support arrays\n\nArrays are parsed now.\n\nReviewed-by: Z\nBREAKING CHANGE: objects are\n  no longer supported\n# comment"
+	c, findings := parseConventionalCommit(msg)
+	assert.Empty(t, findings, "valid message should not result in findings")
+	assert.Equal(t, "feat", c.Type)
+	assert.Equal(t, "parser", c.Scope)
+	assert.True(t, c.Breaking)
+	assert.Equal(t, "support arrays", c.Description)
+	assert.Equal(t, "Arrays are parsed now.", c.Body)
+	assert.Equal(t, []conventionalFooter{
+		{"Reviewed-by", "Z"},
+		{"BREAKING CHANGE", "objects are\n  no longer supported"},
+	}, c.Footers)
+}
+
+func TestConventionalCommits(t *testing.T) {
+	conventionalTests := []struct {
+		name           string
+		msg            string
+		vars           map[string]string
+		statusExpected Status
+		msgExpected    string
+	}{
+		{
+			"valid header",
+			"fix: prevent racing of requests\n# Please enter the commit message",
+			nil,
+			StatusPass,
+			"",
+		},
+		{
+			"missing type",
+			": prevent racing",
+			nil,
+			StatusFail,
+			"MSG:1:1: header has to start with a type, e.g. 'feat: <description>'",
+		},
+		{
+			"unclosed scope",
+			"fix(api: prevent racing",
+			nil,
+			StatusFail,
+			"MSG:1:4: scope is not closed, expected ')'",
+		},
+		{
+			"missing separator",
+			"fix(api) prevent racing",
+			nil,
+			StatusFail,
+			"MSG:1:9: type and scope have to be followed by ': ', e.g. 'feat(parser): <description>'",
+		},
+		{
+			"missing blank line",
+			"fix: prevent racing\nbody",
+			nil,
+			StatusFail,
+			"MSG:2:1: header has to be followed by a blank line",
+		},
+		{
+			"lines of the file",
+			"\n# Please enter the commit message\n\nfix: prevent racing\n# body follows\nbody",
+			nil,
+			StatusFail,
+			"MSG:6:1: header has to be followed by a blank line",
+		},
+		{
+			"disallowed type and scope",
+			"feature(db): add index",
+			map[string]string{"CONVENTIONAL_COMMITS_SCOPES": "api ui"},
+			StatusFail,
+			"MSG:1:1: type 'feature' is not allowed, use one of: feat, fix, docs, style, refactor, perf, test, build, ci, chore, revert\n" +
+				"MSG:1:9: scope 'db' is not allowed, use one of: api, ui",
+		},
+		{
+			"header too long as warning",
+			"fix: prevent racing of requests",
+			map[string]string{"CONVENTIONAL_COMMITS_MAX_HEADER_LENGTH": "10", "CONVENTIONAL_COMMITS_FAIL_ON_MISMATCH": "false"},
+			StatusWarn,
+			"MSG:1:11: header is 31 characters long, the maximum is 10",
+		},
+		{
+			"missing breaking change footer",
+			"feat(api)!: drop v1",
+			map[string]string{"CONVENTIONAL_COMMITS_REQUIRE_BREAKING_FOOTER": "true", "CONVENTIONAL_COMMITS_REQUIRE_SCOPE": "true"},
+			StatusFail,
+			"MSG:1:10: breaking change marker '!' requires a 'BREAKING CHANGE: <description>' footer",
+		},
+		{
+			"present breaking change footer",
+			"feat(api)!: drop v1\n\nBREAKING CHANGE: v1 is gone",
+			map[string]string{"CONVENTIONAL_COMMITS_REQUIRE_BREAKING_FOOTER": "true"},
+			StatusPass,
+			"",
+		},
+		{
+			"invalid variable",
+			"fix: foo",
+			map[string]string{"CONVENTIONAL_COMMITS_MAX_HEADER_LENGTH": "many"},
+			StatusError,
+			"failed parsing 'CONVENTIONAL_COMMITS_MAX_HEADER_LENGTH' variable",
+		},
+	}
+	cc, _ := Get("conventional-commits")
+	dir := t.TempDir()
+	for _, tt := range conventionalTests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, "MSG")
+			_ = os.WriteFile(file, []byte(tt.msg), 0644)
+			res := cc.Run(context.Background(), testInput("commit-msg", tt.vars, []string{file}))
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match executed plugin result")
+			if tt.msgExpected == "" {
+				return
+			}
+			for i := range res.Findings {
+				res.Findings[i].File = filepath.Base(res.Findings[i].File)
+			}
+			if res.Status == StatusError {
+				assert.Equal(t, tt.msgExpected, res.Message)
+				return
+			}
+			assert.Equal(t, "commit message does not follow the Conventional Commits specification\n"+tt.msgExpected, res.String())
+		})
+	}
+}
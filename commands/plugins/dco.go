@@ -0,0 +1,146 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	varDCOFix        = "DCO_FIX"
+	varDCOSkipMerges = "DCO_SKIP_MERGES"
+)
+
+// signOffRegex matches a 'Signed-off-by' trailer and captures name and email
+var signOffRegex = regexp.MustCompile(`(?im)^signed-off-by:[ \t]*(.*?)[ \t]*<([^>]*)>[ \t]*$`)
+
+// DCO enforces a 'Signed-off-by' trailer matching the author as required by the Developer Certificate of Origin
+type DCO struct{}
+
+func (d DCO) ID() string {
+	return "dco"
+}
+
+func (d DCO) Description() string {
+	return "Requires a 'Signed-off-by' trailer matching the author in commit messages and every pushed commit and optionally adds it."
+}
+
+func (d DCO) Hooks() []string {
+	return []string{git.HookCommitMsg, git.HookPrepareCommitMsg, git.HookPrePush}
+}
+
+func (d DCO) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varDCOFix, Type: VarTypeBool, Default: "false", Help: "add the trailer to the commit message within the prepare-commit-msg hook"},
+		{Name: varDCOSkipMerges, Type: VarTypeBool, Default: "true", Help: "do not require a sign-off for pushed merge commits"},
+	}
+}
+
+func (d DCO) Run(ctx context.Context, in RunInput) Result {
+	switch in.Hook {
+	case git.HookCommitMsg, git.HookPrepareCommitMsg:
+		return d.checkMessageFile(in)
+	case git.HookPrePush:
+		return d.checkPushedCommits(in)
+	}
+	return hookUnsupported(in.Hook, d.ID())
+}
+
+// checkMessageFile checks the sign-off of the configured user within the commit-msg hook and adds it within the
+// prepare-commit-msg hook in case fixing is enabled
+func (d DCO) checkMessageFile(in RunInput) Result {
+	if len(in.Args) == 0 {
+		return Errorf("no commit message file provided")
+	}
+	fix, err := in.Vars.Bool(varDCOFix, false)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	if in.Hook == git.HookPrepareCommitMsg && !fix {
+		return Skip("adding the sign-off is disabled, set '%s' to enable it", varDCOFix)
+	}
+	name, email, err := signOffIdentity(in)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	b, err := os.ReadFile(in.Args[0])
+	if err != nil {
+		return Errorf("could not read commit message file: %+v", err)
+	}
+	if isSignedOff(string(b), name, email) {
+		return Pass()
+	}
+	trailer := signOffTrailer(name, email)
+	if in.Hook == git.HookCommitMsg {
+		return Fail(true, fmt.Sprintf("commit message lacks the sign-off '%s'. Use 'git commit -s' to add it", trailer))
+	}
+	if _, err = in.Repo.Command("interpret-trailers", "--in-place", "--if-exists", "addIfDifferent", "--trailer", trailer, in.Args[0]); err != nil {
+		return Errorf("could not add the sign-off: %+v", err)
+	}
+	return Pass()
+}
+
+// signOffIdentity returns the author of the commit whose message is checked or the configured user for new commits
+func signOffIdentity(in RunInput) (string, string, error) {
+	if sha := in.Vars[VarCommitSHA]; sha != "" {
+		commits, err := in.Repo.Commits(sha)
+		if err != nil {
+			return "", "", fmt.Errorf("could not read commit '%s': %+v", sha, err)
+		}
+		return commits[0].AuthorName, commits[0].AuthorEmail, nil
+	}
+	name, _ := in.Repo.Command("config", "user.name")
+	email, _ := in.Repo.Command("config", "user.email")
+	if name == "" || email == "" {
+		return "", "", fmt.Errorf("could not determine the identity from 'git config user.name' and 'git config user.email'")
+	}
+	return name, email, nil
+}
+
+// checkPushedCommits checks that every pushed commit is signed off by its author
+func (d DCO) checkPushedCommits(in RunInput) Result {
+	skipMerges := true
+	if _, ok := in.Vars[varDCOSkipMerges]; ok {
+		var err error
+		if skipMerges, err = in.Vars.Bool(varDCOSkipMerges, false); err != nil {
+			return Errorf("%s", err)
+		}
+	}
+	shas, err := pushedCommits(in)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	commits, err := in.Repo.Commits(shas...)
+	if err != nil {
+		return Errorf("could not read pushed commits: %+v", err)
+	}
+	var findings []Finding
+	for _, c := range commits {
+		if skipMerges && len(c.Parents) > 1 || isSignedOff(c.Message, c.AuthorName, c.AuthorEmail) {
+			continue
+		}
+		findings = append(findings, Finding{Message: fmt.Sprintf("commit %s '%s' lacks the sign-off '%s'",
+			c.ShortSHA(), c.Subject(), signOffTrailer(c.AuthorName, c.AuthorEmail))})
+	}
+	if len(findings) > 0 {
+		return Fail(true, "pushed commits are not signed off by their author. Use 'git rebase --signoff' to add the sign-offs", findings...)
+	}
+	return Pass()
+}
+
+// isSignedOff returns whether the message contains a sign-off of the identity. Emails are compared case-insensitively.
+func isSignedOff(msg string, name string, email string) bool {
+	for _, m := range signOffRegex.FindAllStringSubmatch(cleanCommitMessage(msg), -1) {
+		if m[1] == strings.TrimSpace(name) && strings.EqualFold(m[2], strings.TrimSpace(email)) {
+			return true
+		}
+	}
+	return false
+}
+
+func signOffTrailer(name string, email string) string {
+	return fmt.Sprintf("Signed-off-by: %s <%s>", name, email)
+}
@@ -0,0 +1,70 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDCOMessageFile(t *testing.T) {
+	dcoTests := []struct {
+		name            string
+		hook            string
+		vars            map[string]string
+		msg             string
+		statusExpected  Status
+		messageExpected string
+	}{
+		{"signed off", git.HookCommitMsg, map[string]string{}, "feat: a\n\nSigned-off-by: giks <GIKS@example.com>\n", StatusPass,
+			"feat: a\n\nSigned-off-by: giks <GIKS@example.com>\n"},
+		{"missing sign-off", git.HookCommitMsg, map[string]string{}, "feat: a\n", StatusFail, "feat: a\n"},
+		{"other identity", git.HookCommitMsg, map[string]string{}, "feat: a\n\nSigned-off-by: other <giks@example.com>\n", StatusFail,
+			"feat: a\n\nSigned-off-by: other <giks@example.com>\n"},
+		{"commented sign-off", git.HookCommitMsg, map[string]string{}, "feat: a\n\n# Signed-off-by: giks <giks@example.com>\n", StatusFail,
+			"feat: a\n\n# Signed-off-by: giks <giks@example.com>\n"},
+		{"fix disabled", git.HookPrepareCommitMsg, map[string]string{}, "feat: a\n", StatusSkip, "feat: a\n"},
+		{"fix", git.HookPrepareCommitMsg, map[string]string{"DCO_FIX": "true"}, "feat: a\n\nCo-authored-by: x <x@example.com>\n# comment\n", StatusPass,
+			"feat: a\n\nCo-authored-by: x <x@example.com>\nSigned-off-by: giks <giks@example.com>\n# comment\n"},
+	}
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	d, _ := Get("dco")
+	for _, tt := range dcoTests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+			_ = os.WriteFile(file, []byte(tt.msg), 0644)
+			in := testInput(tt.hook, tt.vars, []string{file})
+			in.Repo = git.NewRepository(tr.AbsDir())
+			res := d.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match result: %s", res)
+			msg, _ := os.ReadFile(file)
+			assert.Equal(t, tt.messageExpected, string(msg), "expected message does not match")
+		})
+	}
+}
+
+func TestDCOPushedCommits(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("a.txt", "a")
+	tr.AddAll()
+	tr.Commit("signed\n\nSigned-off-by: giks <giks@example.com>")
+	tr.WriteFile("a.txt", "b")
+	tr.AddAll()
+	tr.Commit("unsigned")
+	head, _ := tr.Command("rev-parse", "HEAD")
+
+	in := testInput(git.HookPrePush, map[string]string{}, nil)
+	in.Repo = git.NewRepository(tr.AbsDir())
+	in.Stdin = strings.NewReader("refs/heads/main " + strings.TrimSpace(head) + " refs/heads/main " + zeroSHA + "\n")
+	d, _ := Get("dco")
+	res := d.Run(context.Background(), in)
+	assert.Equal(t, StatusFail, res.Status, "unsigned commit should be found: %s", res)
+	if assert.Len(t, res.Findings, 1, "only the unsigned commit should be reported") {
+		assert.Equal(t, "commit "+head[:7]+" 'unsigned' lacks the sign-off 'Signed-off-by: giks <giks@example.com>'", res.Findings[0].Message,
+			"commit should be reported by its SHA")
+	}
+}
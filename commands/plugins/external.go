@@ -0,0 +1,148 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// externalPluginPrefix is the prefix of all executables which can be used as external plugins
+const externalPluginPrefix = "giks-plugin-"
+
+// ExternalProtocolVersion is the version of the JSON request sent to external plugins
+const ExternalProtocolVersion = 1
+
+// ExternalPlugin is an executable named 'giks-plugin-<name>' which receives an ExternalRequest as JSON on stdin
+// and has to print an ExternalResult as JSON on stdout.
+type ExternalPlugin struct {
+	name string
+	path string
+}
+
+// ExternalRequest is passed to external plugins via stdin
+type ExternalRequest struct {
+	Version    int               `json:"version"`
+	Plugin     string            `json:"plugin"`
+	Hook       string            `json:"hook"`
+	Args       []string          `json:"args"`
+	Vars       map[string]string `json:"vars"`
+	WorkingDir string            `json:"working_dir"`
+	Files      ExternalFiles     `json:"files"`
+	// Stdin holds the input git passed to the hook
+	Stdin string `json:"stdin"`
+}
+
+// ExternalFiles holds the file lists giks determined for the current repository state
+type ExternalFiles struct {
+	Staged   []string `json:"staged"`
+	Modified []string `json:"modified"`
+	Head     []string `json:"head"`
+}
+
+// ExternalResult has to be printed by external plugins on stdout
+type ExternalResult struct {
+	// Status is one of 'pass', 'warn', 'fail', 'skip' or 'error'
+	Status   string            `json:"status"`
+	Messages []string          `json:"messages"`
+	Findings []ExternalFinding `json:"findings"`
+}
+
+// ExternalFinding points at a specific location which caused a plugin to warn or fail
+type ExternalFinding struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// FindExternal looks up the executable for an external plugin with the given name. The given directories are
+// searched first followed by the $PATH.
+func FindExternal(name string, dirs ...string) (Plugin, error) {
+	if err := validatePluginName(name); err != nil {
+		return nil, err
+	}
+	bin := externalPluginPrefix + name
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		file := filepath.Join(dir, bin)
+		if fi, err := os.Stat(file); err == nil && !fi.IsDir() && fi.Mode()&0111 != 0 {
+			return ExternalPlugin{name: name, path: file}, nil
+		}
+	}
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return nil, fmt.Errorf("plugin '%s' not found", name)
+	}
+	return ExternalPlugin{name: name, path: path}, nil
+}
+
+func (ep ExternalPlugin) ID() string {
+	return ep.name
+}
+
+func (ep ExternalPlugin) Run(ctx context.Context, in RunInput) Result {
+	var stdin []byte
+	if in.Stdin != nil {
+		stdin, _ = io.ReadAll(in.Stdin)
+	}
+	req := ExternalRequest{
+		Version:    ExternalProtocolVersion,
+		Plugin:     ep.name,
+		Hook:       in.Hook,
+		Args:       in.Args,
+		Vars:       in.Vars,
+		WorkingDir: in.WorkingDir(),
+		Files: ExternalFiles{
+			Staged:   in.Vars.List("GIKS_MIXIN_STAGED_FILES"),
+			Modified: in.Vars.List("GIKS_MIXIN_MODIFIED_FILES"),
+			Head:     in.Vars.List("GIKS_MIXIN_HEAD_FILES"),
+		},
+		Stdin: string(stdin),
+	}
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return Errorf("could not marshal request for external plugin '%s': %+v", ep.name, err)
+	}
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, ep.path)
+	cmd.Dir = in.WorkingDir()
+	cmd.Stdin = bytes.NewReader(reqBytes)
+	cmd.Stdout = &out
+	cmd.Stderr = in.Output
+	runErr := cmd.Run()
+
+	var res ExternalResult
+	if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+		if runErr != nil {
+			return Errorf("external plugin '%s' failed: %+v", ep.name, runErr)
+		}
+		return Errorf("external plugin '%s' returned an invalid result: %+v", ep.name, err)
+	}
+	return res.result()
+}
+
+// result converts the result of an external plugin into a plugin result
+func (er ExternalResult) result() Result {
+	status := Status(er.Status)
+	switch status {
+	case StatusPass, StatusWarn, StatusFail, StatusSkip, StatusError:
+	default:
+		return Errorf("unknown status '%s'", er.Status)
+	}
+	res := Result{Status: status, Message: strings.Join(er.Messages, "\n")}
+	for _, f := range er.Findings {
+		res.Findings = append(res.Findings, Finding{File: f.File, Line: f.Line, Column: f.Column, Message: f.Message})
+	}
+	if res.String() == "" && res.Failed() {
+		res.Message = fmt.Sprintf("plugin reported status '%s'", er.Status)
+	}
+	return res
+}
@@ -0,0 +1,59 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// externalTestPlugin replies depending on the MODE variable found in the request
+const externalTestPlugin = `#!/bin/sh
+input=$(cat)
+case "${input}" in
+  *'"version":1'*) ;;
+  *) echo '{"status":"error","messages":["unsupported protocol version"]}'; exit 1 ;;
+esac
+case "${input}" in
+  *'"MODE":"fail"'*) echo '{"status":"fail","messages":["bad"],"findings":[{"file":"a.go","line":3,"message":"oops"}]}' ;;
+  *'"MODE":"warn"'*) echo '{"status":"warn","messages":["careful"]}' ;;
+  *'"MODE":"garbage"'*) echo 'garbage' ;;
+  *'"staged":["a.go","b.go"]'*) echo '{"status":"pass"}' ;;
+  *) echo '{"status":"error","messages":["files missing"]}' ;;
+esac
+`
+
+func TestExternalPlugin(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "giks-plugin-test"), []byte(externalTestPlugin), 0755)
+
+	_, err := Lookup("absent", dir, dir)
+	assert.Error(t, err, "absent external plugin should not be found")
+	p, err := Lookup("test", dir, dir)
+	assert.NoError(t, err, "external plugin should be found within the plugin directory")
+	assert.Equal(t, "test", p.ID(), "external plugin should use the name without prefix as ID")
+
+	externalTests := []struct {
+		name           string
+		mode           string
+		statusExpected Status
+		msgExpected    string
+	}{
+		{"pass", "pass", StatusPass, ""},
+		{"warn", "warn", StatusWarn, "careful"},
+		{"fail with findings", "fail", StatusFail, "bad\na.go:3: oops"},
+		{"invalid result", "garbage", StatusError, "external plugin 'test' returned an invalid result: invalid character 'g' looking for beginning of value"},
+	}
+	for _, tt := range externalTests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := map[string]string{"MODE": tt.mode, "GIKS_MIXIN_STAGED_FILES": "a.go b.go"}
+			in := testInput("pre-commit", vars, nil)
+			in.Repo = git.NewRepository(dir)
+			res := p.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match executed plugin result")
+			assert.Equal(t, tt.msgExpected, res.String(), "message does not match")
+		})
+	}
+}
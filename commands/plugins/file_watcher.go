@@ -2,59 +2,256 @@ package plugins
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"fmt"
+	"github.com/jenpet/giks/git"
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 )
 
 const (
-	varFilePattern = "FILE_WATCHER_PATTERN"
-	varCommand     = "FILE_WATCHER_COMMAND"
-	varFileList    = "FILE_WATCHER_FILES_LIST"
+	varFilePattern     = "FILE_WATCHER_PATTERN"
+	varFilePatternType = "FILE_WATCHER_PATTERN_TYPE"
+	varCommand         = "FILE_WATCHER_COMMAND"
+	varFileList        = "FILE_WATCHER_FILES_LIST"
+	varFileWatcherMode = "FILE_WATCHER_MODE"
+	varRestage         = "FILE_WATCHER_RESTAGE"
+)
+
+const (
+	patternTypeRegex = "regex"
+	patternTypeGlob  = "glob"
+
+	fileWatcherModeOnce    = "once"
+	fileWatcherModePerFile = "per-file"
+
+	placeholderFile  = "{file}"
+	placeholderFiles = "{files}"
+	placeholderDir   = "{dir}"
 )
 
 type FileWatcher struct{}
 
+// fileCommand is a command which is executed for the given files
+type fileCommand struct {
+	command string
+	files   []string
+}
+
 func (fw FileWatcher) ID() string {
 	return "file-watcher"
 }
 
-func (fw FileWatcher) Run(workingDir string, hook string, vars map[string]string, args []string) (bool, error) {
-	pattern, err := extractStringVar(varFilePattern, vars, true)
-	if err != nil {
-		return false, err
+func (fw FileWatcher) Description() string {
+	return "Runs a shell command once or per file in case files of a file list match a pattern."
+}
+
+func (fw FileWatcher) Hooks() []string {
+	return nil
+}
+
+func (fw FileWatcher) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varFilePattern, Type: VarTypeString, Required: true, Help: "pattern matched against every file of the file list"},
+		{Name: varFilePatternType, Type: VarTypeString, Default: patternTypeRegex, Help: "either 'regex' or 'glob'. Globs without a '/' are matched against the file name"},
+		{Name: varCommand, Type: VarTypeString, Required: true,
+			Help: "shell command which is executed in case a file matches. Supports the placeholders {files}, {dir} and in per-file mode {file}"},
+		{Name: varFileList, Type: VarTypeList, Help: "space separated list of files, usually a mixin like 'GIKS_MIXIN_STAGED_FILES'"},
+		{Name: varFileWatcherMode, Type: VarTypeString, Default: fileWatcherModeOnce, Help: "either run the command 'once' or in parallel 'per-file'"},
+		{Name: varRestage, Type: VarTypeBool, Default: "false", Help: "stage matching files which were modified by the command"},
 	}
+}
 
-	var cmd *exec.Cmd
-	err = extractVar(varCommand, vars, func(val string) error {
-		cmd = exec.Command("sh", []string{"-c", val}...)
-		cmd.Dir = workingDir
-		return nil
-	}, true)
+// Run never blocks the hook, neither failing commands nor misconfigurations prevent further steps from being executed
+func (fw FileWatcher) Run(ctx context.Context, in RunInput) Result {
+	pattern, err := in.Vars.String(varFilePattern, true)
+	if err != nil {
+		return Fail(false, err.Error())
+	}
+	patternType, err := in.Vars.String(varFilePatternType, false)
+	if err != nil {
+		return Fail(false, err.Error())
+	}
+	command, err := in.Vars.String(varCommand, true)
+	if err != nil {
+		return Fail(false, err.Error())
+	}
+	mode, err := in.Vars.String(varFileWatcherMode, false)
+	if err != nil {
+		return Fail(false, err.Error())
+	}
+	restage, err := in.Vars.Bool(varRestage, false)
+	if err != nil {
+		return Fail(false, err.Error())
+	}
 
-	filesString, err := extractStringVar(varFileList, vars, false)
+	files, err := matchingFiles(in.Vars.List(varFileList), pattern, patternType)
 	if err != nil {
-		return false, err
-	}
-	files := strings.Split(filesString, " ")
-	if singleFileMatchesPattern(files, pattern) {
-		var buf bytes.Buffer
-		cmd.Stdout = &buf
-		cmd.Stderr = &buf
-		err = cmd.Run()
+		return Fail(false, err.Error())
+	}
+	if len(files) == 0 {
+		return Pass()
+	}
+	commands, err := fileCommands(command, files, mode)
+	if err != nil {
+		return Fail(false, err.Error())
+	}
+
+	var checksums map[string][32]byte
+	if restage {
+		checksums = fileChecksums(in.WorkingDir(), files)
+	}
+	findings := runFileCommands(ctx, in.WorkingDir(), commands)
+	if restage {
+		if err = restageModified(in, files, checksums); err != nil {
+			return Fail(false, err.Error())
+		}
+	}
+	if len(findings) > 0 {
+		return Fail(false, "files matched pattern but command failed", findings...)
+	}
+	return Pass()
+}
+
+// matchingFiles returns the files which match the pattern
+func matchingFiles(files []string, pattern string, patternType string) ([]string, error) {
+	var match func(file string) bool
+	switch patternType {
+	case "", patternTypeRegex:
+		r, err := regexp.Compile(pattern)
 		if err != nil {
-			return false, fmt.Errorf("files matched pattern but command failed: %+v: %s", err, buf.String())
+			return nil, fmt.Errorf("provided pattern '%s' can not be used as a regexp", pattern)
+		}
+		match = r.MatchString
+	case patternTypeGlob:
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("provided glob '%s' is malformed", pattern)
+		}
+		match = func(file string) bool { return matchGlob(pattern, file) }
+	default:
+		return nil, fmt.Errorf("unknown pattern type '%s'. Supported types are '%s' and '%s'", patternType, patternTypeRegex, patternTypeGlob)
+	}
+	var matching []string
+	for _, file := range files {
+		if match(file) {
+			matching = append(matching, file)
+		}
+	}
+	return matching, nil
+}
+
+// fileCommands replaces the placeholders of the command either once for all files or for every single file
+func fileCommands(command string, files []string, mode string) ([]fileCommand, error) {
+	switch mode {
+	case "", fileWatcherModeOnce:
+		if strings.Contains(command, placeholderFile) {
+			return nil, fmt.Errorf("placeholder '%s' requires mode '%s'", placeholderFile, fileWatcherModePerFile)
+		}
+		var dirs []string
+		for _, file := range files {
+			dirs = git.AppendUnique(dirs, path.Dir(file))
+		}
+		r := strings.NewReplacer(placeholderFiles, shellQuoteAll(files), placeholderDir, shellQuoteAll(dirs))
+		return []fileCommand{{command: r.Replace(command), files: files}}, nil
+	case fileWatcherModePerFile:
+		commands := make([]fileCommand, len(files))
+		for i, file := range files {
+			r := strings.NewReplacer(placeholderFile, shellQuote(file), placeholderFiles, shellQuote(file), placeholderDir, shellQuote(path.Dir(file)))
+			commands[i] = fileCommand{command: r.Replace(command), files: []string{file}}
+		}
+		return commands, nil
+	}
+	return nil, fmt.Errorf("unknown mode '%s'. Supported modes are '%s' and '%s'", mode, fileWatcherModeOnce, fileWatcherModePerFile)
+}
+
+// runFileCommands runs the commands in parallel and returns a finding for every failed command. The output of a
+// command is only reported in case it failed.
+func runFileCommands(ctx context.Context, workingDir string, commands []fileCommand) []Finding {
+	failures := make([]*Finding, len(commands))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				var buf bytes.Buffer
+				cmd := exec.CommandContext(ctx, "sh", "-c", commands[i].command)
+				cmd.Dir = workingDir
+				cmd.Stdout = &buf
+				cmd.Stderr = &buf
+				if err := cmd.Run(); err != nil {
+					f := Finding{Message: fmt.Sprintf("command failed: %+v: %s", err, strings.TrimSpace(buf.String()))}
+					if len(commands[i].files) == 1 {
+						f.File = commands[i].files[0]
+					}
+					failures[i] = &f
+				}
+			}
+		}()
+	}
+	for i := range commands {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var findings []Finding
+	for _, f := range failures {
+		if f != nil {
+			findings = append(findings, *f)
 		}
-		return true, nil
 	}
-	return true, nil
+	return findings
 }
 
-func singleFileMatchesPattern(files []string, pattern string) bool {
+// fileChecksums returns the checksums of all existing files
+func fileChecksums(workingDir string, files []string) map[string][32]byte {
+	checksums := map[string][32]byte{}
 	for _, file := range files {
-		if err := matchString(file, pattern); err == nil {
-			return true
+		if b, err := os.ReadFile(filepath.Join(workingDir, file)); err == nil {
+			checksums[file] = sha256.Sum256(b)
+		}
+	}
+	return checksums
+}
+
+// restageModified adds all files to the index whose checksum changed while running the command
+func restageModified(in RunInput, files []string, before map[string][32]byte) error {
+	after := fileChecksums(in.WorkingDir(), files)
+	var modified []string
+	for file, sum := range after {
+		if prev, ok := before[file]; !ok || prev != sum {
+			modified = append(modified, file)
 		}
 	}
-	return false
+	if len(modified) == 0 {
+		return nil
+	}
+	sort.Strings(modified)
+	if _, err := in.Repo.Command(append([]string{"add", "--"}, modified...)...); err != nil {
+		return fmt.Errorf("could not restage modified files: %+v", err)
+	}
+	return nil
+}
+
+// shellQuote quotes the string so it is passed as a single argument to the shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func shellQuoteAll(list []string) string {
+	quoted := make([]string, len(list))
+	for i, s := range list {
+		quoted[i] = shellQuote(s)
+	}
+	return strings.Join(quoted, " ")
 }
@@ -1,10 +1,14 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -14,40 +18,35 @@ func TestFileWatcher(t *testing.T) {
 		name           string
 		pattern        string
 		files          string
-		okExpected     bool
-		errExpected    bool
+		statusExpected Status
 		cmdExcExpected bool
 	}{
 		{
 			"files match pattern",
 			".*.go",
 			"../foo/bar.go bar.go foo.go",
-			true,
-			false,
+			StatusPass,
 			true,
 		},
 		{
 			"files dont match pattern",
 			".*.go",
 			"../foo/bar.js bar.js foo.ts",
-			true,
-			false,
+			StatusPass,
 			false,
 		},
 		{
 			"empty pattern",
 			"",
 			"foo.go bar.ts",
-			false,
-			true,
+			StatusWarn,
 			false,
 		},
 		{
 			"empty files",
 			".*.go",
 			"",
-			true,
-			false,
+			StatusPass,
 			false,
 		},
 	}
@@ -61,9 +60,8 @@ func TestFileWatcher(t *testing.T) {
 				"FILE_WATCHER_COMMAND":    "touch " + file,
 				"FILE_WATCHER_FILES_LIST": tt.files,
 			}
-			ok, err := fw.Run("", "pre-commit", vars, nil)
-			assert.Equal(t, tt.okExpected, ok, "expected bool does not match executed plugin result")
-			assert.Equal(t, tt.errExpected, err != nil, "error expectation and result does not match")
+			res := fw.Run(context.Background(), testInput("pre-commit", vars, nil))
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match executed plugin result")
 			fh, err := os.Stat(file)
 			if tt.cmdExcExpected {
 				assert.NotNilf(t, fh, "command should have been executed successfully")
@@ -79,3 +77,87 @@ func TestFileWatcher(t *testing.T) {
 func testFileName() string {
 	return fmt.Sprintf("../../test/output/%d.out", time.Now().UnixNano())
 }
+
+func TestFileWatcherPlaceholders(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	placeholderTests := []struct {
+		name           string
+		vars           map[string]string
+		statusExpected Status
+		outExpected    string
+		fileExpected   string
+	}{
+		{
+			"files and dirs once",
+			map[string]string{"FILE_WATCHER_PATTERN": `\.go$`, "FILE_WATCHER_COMMAND": "echo {files} {dir} > out.txt",
+				"FILE_WATCHER_FILES_LIST": "a.go cmd/b.go c.js"},
+			StatusPass,
+			"a.go cmd/b.go . cmd\n",
+			"",
+		},
+		{
+			"glob per file",
+			map[string]string{"FILE_WATCHER_PATTERN": "*.go", "FILE_WATCHER_PATTERN_TYPE": "glob", "FILE_WATCHER_MODE": "per-file",
+				"FILE_WATCHER_COMMAND":    "echo {file} > \"$(basename {file}).out\" && cat *.out > out.txt",
+				"FILE_WATCHER_FILES_LIST": "cmd/b.go c.js"},
+			StatusPass,
+			"cmd/b.go\n",
+			"",
+		},
+		{
+			"file placeholder requires per-file mode",
+			map[string]string{"FILE_WATCHER_PATTERN": ".*", "FILE_WATCHER_COMMAND": "echo {file}", "FILE_WATCHER_FILES_LIST": "a.go"},
+			StatusWarn,
+			"",
+			"",
+		},
+		{
+			"failing file is reported",
+			map[string]string{"FILE_WATCHER_PATTERN": ".*", "FILE_WATCHER_MODE": "per-file", "FILE_WATCHER_COMMAND": "test {file} = a.go",
+				"FILE_WATCHER_FILES_LIST": "a.go b.go"},
+			StatusWarn,
+			"",
+			"b.go",
+		},
+	}
+	fw, _ := Get("file-watcher")
+	for _, tt := range placeholderTests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := testInput("pre-commit", tt.vars, nil)
+			in.Repo = git.NewRepository(tr.AbsDir())
+			res := fw.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match executed plugin result: %s", res)
+			if tt.outExpected != "" {
+				b, _ := os.ReadFile(filepath.Join(tr.AbsDir(), "out.txt"))
+				assert.Equal(t, tt.outExpected, string(b), "command should have been executed with replaced placeholders")
+			}
+			if tt.fileExpected != "" && assert.Len(t, res.Findings, 1, "only the failing file should be reported") {
+				assert.Equal(t, tt.fileExpected, res.Findings[0].File, "failing file should be reported")
+			}
+		})
+	}
+}
+
+func TestFileWatcherRestage(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("a.txt", "unformatted")
+	tr.WriteFile("b.txt", "untouched")
+	tr.AddAll()
+
+	vars := map[string]string{
+		"FILE_WATCHER_PATTERN":    `\.txt$`,
+		"FILE_WATCHER_COMMAND":    "echo formatted > a.txt && echo unstaged > other.md",
+		"FILE_WATCHER_FILES_LIST": "a.txt b.txt",
+		"FILE_WATCHER_RESTAGE":    "true",
+	}
+	in := testInput("pre-commit", vars, nil)
+	in.Repo = git.NewRepository(tr.AbsDir())
+	fw, _ := Get("file-watcher")
+	res := fw.Run(context.Background(), in)
+	assert.Equal(t, StatusPass, res.Status, "file watcher should pass: %s", res)
+
+	staged, _ := tr.Command("show", ":a.txt")
+	assert.Equal(t, "formatted\n", staged, "modified file should have been restaged")
+	status, _ := tr.Command("status", "--porcelain")
+	assert.Contains(t, status, "?? other.md", "non matching files should not be staged")
+}
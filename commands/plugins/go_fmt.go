@@ -0,0 +1,157 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"go/format"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	varGoFmtExcludes = "GO_FMT_EXCLUDES"
+	varGoFmtFix      = "GO_FMT_FIX"
+	varGoFmtVet      = "GO_FMT_VET"
+)
+
+// vetLineRegex matches the diagnostics printed by 'go vet', e.g. 'main.go:12:3: unreachable code'
+var vetLineRegex = regexp.MustCompile(`^(.+\.go):(\d+):(\d+): (.*)$`)
+
+// GoFmt formats the staged Go files and optionally vets the packages containing them
+type GoFmt struct{}
+
+func (gf GoFmt) ID() string {
+	return "go-fmt"
+}
+
+func (gf GoFmt) Description() string {
+	return "Checks or fixes the formatting of staged Go files and optionally runs 'go vet' on their packages."
+}
+
+func (gf GoFmt) Hooks() []string {
+	return []string{git.HookPreCommit}
+}
+
+func (gf GoFmt) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varGoFmtExcludes, Type: VarTypeList, Help: "space separated list of globs of files which are not formatted, e.g. 'vendor/ *.pb.go'"},
+		{Name: varGoFmtFix, Type: VarTypeBool, Default: "false", Help: "format the files within the index and the working tree instead of failing"},
+		{Name: varGoFmtVet, Type: VarTypeBool, Default: "false", Help: "run 'go vet' on the packages containing staged Go files"},
+	}
+}
+
+func (gf GoFmt) Run(ctx context.Context, in RunInput) Result {
+	if in.Hook != git.HookPreCommit {
+		return hookUnsupported(in.Hook, gf.ID())
+	}
+	fix, err := in.Vars.Bool(varGoFmtFix, false)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	vet, err := in.Vars.Bool(varGoFmtVet, false)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	blobs, contents, err := stagedContents(in, in.Vars.List(varGoFmtExcludes))
+	if err != nil {
+		return Errorf("%s", err)
+	}
+
+	var findings []Finding
+	var dirs []string
+	for i, b := range blobs {
+		if path.Ext(b.Path) != ".go" {
+			continue
+		}
+		dirs = git.AppendUnique(dirs, path.Dir(b.Path))
+		formatted, err := formatGoSource(contents[i])
+		if err != nil {
+			findings = append(findings, Finding{File: b.Path, Message: fmt.Sprintf("could not be formatted: %+v", err)})
+			continue
+		}
+		if formatted == contents[i] {
+			continue
+		}
+		if !fix {
+			findings = append(findings, Finding{File: b.Path, Message: "file is not formatted"})
+			continue
+		}
+		if err = fixStaged(in, b, contents[i], formatGoSource); err != nil {
+			return Errorf("could not fix '%s': %+v", b.Path, err)
+		}
+		_, _ = fmt.Fprintf(in.Output, "Formatted '%s'\n", b.Path)
+	}
+	if len(findings) > 0 {
+		return Fail(true, fmt.Sprintf("staged Go files are not formatted. Set '%s' to format them automatically", varGoFmtFix), findings...)
+	}
+	if vet && len(dirs) > 0 {
+		findings, err = goVet(ctx, in.WorkingDir(), dirs)
+		if err != nil {
+			return Errorf("%s", err)
+		}
+		if len(findings) > 0 {
+			return Fail(true, "'go vet' reported problems", findings...)
+		}
+	}
+	return Pass()
+}
+
+// formatGoSource formats the content in the same way as gofmt
+func formatGoSource(content string) (string, error) {
+	formatted, err := format.Source([]byte(content))
+	return string(formatted), err
+}
+
+// goVet vets the packages within the given directories of the working tree and returns a finding for every reported
+// problem. Directories which do not exist in the working tree anymore are ignored.
+func goVet(ctx context.Context, workingDir string, dirs []string) ([]Finding, error) {
+	var pkgs []string
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(workingDir, dir)); err == nil {
+			pkgs = append(pkgs, "./"+dir)
+		}
+	}
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return nil, fmt.Errorf("could not find the go binary: %+v", err)
+	}
+	var buf bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", append([]string{"vet"}, pkgs...)...)
+	cmd.Dir = workingDir
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+	if err := cmd.Run(); err == nil {
+		return nil, nil
+	} else if _, ok := err.(*exec.ExitError); !ok {
+		return nil, fmt.Errorf("could not run 'go vet': %+v", err)
+	}
+	return parseVetOutput(buf.String()), nil
+}
+
+// parseVetOutput returns a finding for every diagnostic of 'go vet'. Lines which are no diagnostics, e.g. package
+// headers, are dropped unless no diagnostic could be found at all.
+func parseVetOutput(output string) []Finding {
+	var findings []Finding
+	for _, line := range strings.Split(output, "\n") {
+		m := vetLineRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		l, _ := strconv.Atoi(m[2])
+		c, _ := strconv.Atoi(m[3])
+		findings = append(findings, Finding{File: filepath.ToSlash(strings.TrimPrefix(m[1], "./")), Line: l, Column: c, Message: m[4]})
+	}
+	if len(findings) == 0 {
+		findings = append(findings, Finding{Message: strings.TrimSpace(output)})
+	}
+	return findings
+}
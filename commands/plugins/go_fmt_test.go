@@ -0,0 +1,60 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGoFmt(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("go.mod", "module example.com/fmt\n\ngo 1.16\n")
+	tr.WriteFile("main.go", "package main\n\nfunc main() {\n}\n")
+	tr.WriteFile("pkg/ugly.go", "package pkg\nfunc Ugly( ) int {\nreturn 1}\n")
+	tr.WriteFile("gen/generated.go", "package gen\nvar  X = 1\n")
+	tr.WriteFile("README.md", "not  go\n")
+	tr.AddAll()
+	// unstaged change within the working tree which must not be staged
+	tr.WriteFile("pkg/ugly.go", "package pkg\nfunc Ugly( ) int {\nreturn 2}\n")
+
+	gf, _ := Get("go-fmt")
+	in := testInput(git.HookPreCommit, map[string]string{"GO_FMT_EXCLUDES": "gen/"}, nil)
+	in.Repo = git.NewRepository(tr.AbsDir())
+	res := gf.Run(context.Background(), in)
+	assert.Equal(t, StatusFail, res.Status, "unformatted files should fail the hook: %s", res)
+	if assert.Len(t, res.Findings, 1, "only the unformatted file should be reported") {
+		assert.Equal(t, "pkg/ugly.go", res.Findings[0].File, "unformatted file should be reported by name")
+	}
+
+	in.Vars = map[string]string{"GO_FMT_EXCLUDES": "gen/", "GO_FMT_FIX": "true"}
+	res = gf.Run(context.Background(), in)
+	assert.Equal(t, StatusPass, res.Status, "files should be formatted: %s", res)
+	staged, _ := tr.Command("show", ":pkg/ugly.go")
+	assert.Equal(t, "package pkg\n\nfunc Ugly() int {\n\treturn 1\n}\n", staged, "staged content should be formatted without staging unstaged changes")
+	worktree, _ := os.ReadFile(filepath.Join(tr.AbsDir(), "pkg/ugly.go"))
+	assert.Equal(t, "package pkg\n\nfunc Ugly() int {\n\treturn 2\n}\n", string(worktree), "working tree should be formatted as well")
+	excluded, _ := tr.Command("show", ":gen/generated.go")
+	assert.Equal(t, "package gen\nvar  X = 1\n", excluded, "excluded files should not be formatted")
+
+	tr.WriteFile("main.go", "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Printf(\"%d\", \"a\")\n}\n")
+	tr.AddAll()
+	in.Vars = map[string]string{"GO_FMT_EXCLUDES": "gen/", "GO_FMT_VET": "true"}
+	res = gf.Run(context.Background(), in)
+	assert.Equal(t, StatusFail, res.Status, "vet problems should fail the hook: %s", res)
+	if assert.Len(t, res.Findings, 1, "vet problem should be reported") {
+		assert.Equal(t, "main.go", res.Findings[0].File, "vet problem should be located in the file")
+		assert.Equal(t, 6, res.Findings[0].Line, "vet problem should be located in the line")
+	}
+}
+
+func TestParseVetOutput(t *testing.T) {
+	findings := parseVetOutput("# example.com/fmt\n./main.go:6:2: fmt.Printf format %d has arg \"a\" of wrong type string\n")
+	assert.Equal(t, []Finding{{File: "main.go", Line: 6, Column: 2, Message: "fmt.Printf format %d has arg \"a\" of wrong type string"}},
+		findings, "diagnostics should be parsed")
+	findings = parseVetOutput("go: cannot find main module\n")
+	assert.Equal(t, []Finding{{Message: "go: cannot find main module"}}, findings, "unknown output should be reported as is")
+}
@@ -0,0 +1,328 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	varLicenseTemplate     = "LICENSE_HEADER_TEMPLATE"
+	varLicenseTemplateFile = "LICENSE_HEADER_TEMPLATE_FILE"
+	varLicenseOwner        = "LICENSE_HEADER_OWNER"
+	varLicenseStyles       = "LICENSE_HEADER_STYLES"
+	varLicenseIncludes     = "LICENSE_HEADER_INCLUDES"
+	varLicenseExcludes     = "LICENSE_HEADER_EXCLUDES"
+	varLicenseFix          = "LICENSE_HEADER_FIX"
+	varLicenseUpdateYear   = "LICENSE_HEADER_UPDATE_YEAR"
+)
+
+const (
+	placeholderYear  = "{year}"
+	placeholderOwner = "{owner}"
+)
+
+// licensePlaceholderRegex matches the placeholders of a license header template
+var licensePlaceholderRegex = regexp.MustCompile(`\{year\}|\{owner\}`)
+
+// licensePrologueRegex matches the lines which have to stay in front of a license header, i.e. a shebang, an XML
+// declaration or Go build constraints including the blank lines which have to follow them
+var licensePrologueRegex = regexp.MustCompile(`\A(?:#![^\n]*\n|<\?xml[^\n]*\?>[^\n]*\n|(?://go:build[^\n]*\n|// \+build[^\n]*\n)+(?:[ \t]*\r?\n)*)?`)
+
+// commentStyle describes how a license header is commented out. Block comments have a start and end line.
+type commentStyle struct {
+	start  string
+	prefix string
+	end    string
+}
+
+var (
+	lineCommentStyle  = commentStyle{prefix: "// "}
+	hashCommentStyle  = commentStyle{prefix: "# "}
+	dashCommentStyle  = commentStyle{prefix: "-- "}
+	blockCommentStyle = commentStyle{start: "/*", prefix: " * ", end: " */"}
+	xmlCommentStyle   = commentStyle{start: "<!--", prefix: "  ", end: "-->"}
+)
+
+// defaultCommentStyles maps file extensions to the comment style used for their license header
+var defaultCommentStyles = map[string]commentStyle{
+	".go": lineCommentStyle, ".c": lineCommentStyle, ".h": lineCommentStyle, ".cc": lineCommentStyle,
+	".cpp": lineCommentStyle, ".hpp": lineCommentStyle, ".java": lineCommentStyle, ".js": lineCommentStyle,
+	".jsx": lineCommentStyle, ".ts": lineCommentStyle, ".tsx": lineCommentStyle, ".kt": lineCommentStyle,
+	".rs": lineCommentStyle, ".scala": lineCommentStyle, ".swift": lineCommentStyle, ".cs": lineCommentStyle,
+	".dart": lineCommentStyle, ".proto": lineCommentStyle,
+	".py": hashCommentStyle, ".sh": hashCommentStyle, ".bash": hashCommentStyle, ".rb": hashCommentStyle,
+	".pl": hashCommentStyle, ".r": hashCommentStyle, ".yml": hashCommentStyle, ".yaml": hashCommentStyle,
+	".toml": hashCommentStyle, ".tf": hashCommentStyle,
+	".sql": dashCommentStyle, ".lua": dashCommentStyle, ".hs": dashCommentStyle,
+	".css": blockCommentStyle, ".scss": blockCommentStyle, ".less": blockCommentStyle,
+	".html": xmlCommentStyle, ".htm": xmlCommentStyle, ".xml": xmlCommentStyle, ".svg": xmlCommentStyle, ".vue": xmlCommentStyle,
+}
+
+// LicenseHeader checks that staged added files carry a license header and optionally keeps its year up to date
+type LicenseHeader struct{}
+
+// licenseHeader is a template rendered for a single comment style
+type licenseHeader struct {
+	// text is the header which is inserted into files
+	text string
+	// regex matches an existing header with any year. Every year is captured by a group.
+	regex *regexp.Regexp
+}
+
+func (lh LicenseHeader) ID() string {
+	return "license-header"
+}
+
+func (lh LicenseHeader) Description() string {
+	return "Checks and optionally inserts license headers into staged added files and updates their year on modified files."
+}
+
+func (lh LicenseHeader) Hooks() []string {
+	return []string{git.HookPreCommit}
+}
+
+func (lh LicenseHeader) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varLicenseTemplate, Type: VarTypeString,
+			Help: fmt.Sprintf("uncommented header text supporting the placeholders '%s' and '%s'", placeholderYear, placeholderOwner)},
+		{Name: varLicenseTemplateFile, Type: VarTypeString,
+			Help: fmt.Sprintf("path to a file relative to the repository holding the header text if '%s' is not set", varLicenseTemplate)},
+		{Name: varLicenseOwner, Type: VarTypeString, Help: fmt.Sprintf("value of the '%s' placeholder", placeholderOwner)},
+		{Name: varLicenseStyles, Type: VarTypeString,
+			Help: "newline separated list of '.ext=prefix' or '.ext=start|prefix|end' entries adding or overriding comment styles per file extension"},
+		{Name: varLicenseIncludes, Type: VarTypeList, Help: "space separated list of globs of files which are checked, all files with a known comment style are checked if empty"},
+		{Name: varLicenseExcludes, Type: VarTypeList, Help: "space separated list of globs of files which are not checked, e.g. 'vendor/ *.pb.go'"},
+		{Name: varLicenseFix, Type: VarTypeBool, Default: "false", Help: "insert or update headers within the index and the working tree instead of failing"},
+		{Name: varLicenseUpdateYear, Type: VarTypeBool, Default: "false",
+			Help: "require the year of headers within modified files to end with the current year, e.g. '2019' becomes '2019-2024'"},
+	}
+}
+
+func (lh LicenseHeader) Run(ctx context.Context, in RunInput) Result {
+	if in.Hook != git.HookPreCommit {
+		return hookUnsupported(in.Hook, lh.ID())
+	}
+	template, err := licenseTemplate(in)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	owner := in.Vars[varLicenseOwner]
+	if strings.Contains(template, placeholderOwner) && owner == "" {
+		return Errorf("template contains the placeholder '%s' but variable '%s' is not set", placeholderOwner, varLicenseOwner)
+	}
+	styles, err := commentStyles(in.Vars[varLicenseStyles])
+	if err != nil {
+		return Errorf("variable '%s' is invalid: %s", varLicenseStyles, err)
+	}
+	fix, err := in.Vars.Bool(varLicenseFix, false)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	updateYear, err := in.Vars.Bool(varLicenseUpdateYear, false)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	blobs, contents, err := stagedContents(in, in.Vars.List(varLicenseExcludes))
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	includes := in.Vars.List(varLicenseIncludes)
+	year := time.Now().Year()
+	// years of existing headers are only checked if requested
+	minYear := 0
+	if updateYear {
+		minYear = year
+	}
+
+	headers := map[commentStyle]licenseHeader{}
+	var findings []Finding
+	for i, b := range blobs {
+		style, ok := styles[strings.ToLower(path.Ext(b.Path))]
+		if !ok || isBinary(contents[i]) || len(includes) > 0 && !matchesAnyGlob(includes, b.Path) {
+			continue
+		}
+		if b.Status != "A" && !(updateYear && b.Status == "M") {
+			continue
+		}
+		header, ok := headers[style]
+		if !ok {
+			header = newLicenseHeader(template, owner, year, style)
+			headers[style] = header
+		}
+		problems := header.check(b.Path, contents[i], minYear, b.Status == "A")
+		if len(problems) == 0 {
+			continue
+		}
+		if !fix {
+			findings = append(findings, problems...)
+			continue
+		}
+		fixHeader := func(content string) (string, error) { return header.fix(content, minYear, b.Status == "A"), nil }
+		if err = fixStaged(in, b, contents[i], fixHeader); err != nil {
+			return Errorf("could not fix '%s': %+v", b.Path, err)
+		}
+		_, _ = fmt.Fprintf(in.Output, "Fixed license header of '%s'\n", b.Path)
+	}
+	if len(findings) > 0 {
+		return Fail(true, fmt.Sprintf("staged files have missing or outdated license headers. Set '%s' to fix them automatically", varLicenseFix), findings...)
+	}
+	return Pass()
+}
+
+// licenseTemplate returns the configured template without trailing newlines
+func licenseTemplate(in RunInput) (string, error) {
+	template := in.Vars[varLicenseTemplate]
+	if template == "" && in.Vars[varLicenseTemplateFile] != "" {
+		b, err := os.ReadFile(filepath.Join(in.WorkingDir(), in.Vars[varLicenseTemplateFile]))
+		if err != nil {
+			return "", fmt.Errorf("could not read license header template: %+v", err)
+		}
+		template = string(b)
+	}
+	template = strings.TrimRight(strings.ReplaceAll(template, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(template) == "" {
+		return "", fmt.Errorf("either variable '%s' or '%s' has to be set", varLicenseTemplate, varLicenseTemplateFile)
+	}
+	return template, nil
+}
+
+// commentStyles returns the default comment styles extended by the '.ext=prefix' or '.ext=start|prefix|end' entries
+func commentStyles(list string) (map[string]commentStyle, error) {
+	styles := map[string]commentStyle{}
+	for ext, style := range defaultCommentStyles {
+		styles[ext] = style
+	}
+	for _, line := range strings.Split(list, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		ext := strings.ToLower(strings.TrimSpace(parts[0]))
+		if len(parts) != 2 || !strings.HasPrefix(ext, ".") || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("style '%s' is malformed, expected '.ext=prefix' or '.ext=start|prefix|end'", line)
+		}
+		tokens := strings.Split(parts[1], "|")
+		switch len(tokens) {
+		case 1:
+			styles[ext] = commentStyle{prefix: strings.TrimSpace(tokens[0]) + " "}
+		case 3:
+			styles[ext] = commentStyle{start: strings.TrimSpace(tokens[0]), prefix: tokens[1], end: tokens[2]}
+		default:
+			return nil, fmt.Errorf("style '%s' is malformed, expected '.ext=prefix' or '.ext=start|prefix|end'", line)
+		}
+	}
+	return styles, nil
+}
+
+// newLicenseHeader renders the template in the comment style. Placeholders are replaced by the values, the regex
+// accepts any year or year range instead.
+func newLicenseHeader(template string, owner string, year int, style commentStyle) licenseHeader {
+	var lines []string
+	if style.start != "" {
+		lines = append(lines, style.start)
+	}
+	for _, line := range strings.Split(template, "\n") {
+		lines = append(lines, strings.TrimRight(style.prefix+line, " \t"))
+	}
+	if style.end != "" {
+		lines = append(lines, style.end)
+	}
+
+	var patterns []string
+	for _, line := range lines {
+		var p strings.Builder
+		last := 0
+		for _, loc := range licensePlaceholderRegex.FindAllStringIndex(line, -1) {
+			p.WriteString(regexp.QuoteMeta(line[last:loc[0]]))
+			if line[loc[0]:loc[1]] == placeholderYear {
+				p.WriteString(`(\d{4}(?:\s*-\s*\d{4})?)`)
+			} else {
+				p.WriteString(regexp.QuoteMeta(owner))
+			}
+			last = loc[1]
+		}
+		p.WriteString(regexp.QuoteMeta(line[last:]))
+		patterns = append(patterns, p.String()+`[ \t]*`)
+	}
+	text := strings.Join(lines, "\n")
+	text = strings.ReplaceAll(text, placeholderYear, strconv.Itoa(year))
+	text = strings.ReplaceAll(text, placeholderOwner, owner)
+	return licenseHeader{
+		text:  text,
+		regex: regexp.MustCompile(`\A(?:[ \t]*\r?\n)*` + strings.Join(patterns, `\r?\n`) + `(?:\r?\n|\z)`),
+	}
+}
+
+// check reports a missing header if the header is required and years of an existing header which end before the
+// given year
+func (h licenseHeader) check(file string, content string, year int, required bool) []Finding {
+	prologue := licensePrologueRegex.FindString(content)
+	loc := h.regex.FindStringSubmatchIndex(content[len(prologue):])
+	if loc == nil {
+		if required {
+			return []Finding{{File: file, Line: 1, Column: 1, Message: "license header is missing"}}
+		}
+		return nil
+	}
+	var findings []Finding
+	for g := 2; g+1 < len(loc); g += 2 {
+		start := len(prologue) + loc[g]
+		found := content[start : len(prologue)+loc[g+1]]
+		if updated := updateYearRange(found, year); updated != found {
+			line := strings.Count(content[:start], "\n") + 1
+			column := start - strings.LastIndex(content[:start], "\n")
+			findings = append(findings, Finding{File: file, Line: line, Column: column,
+				Message: fmt.Sprintf("license header year '%s' should be '%s'", found, updated)})
+		}
+	}
+	return findings
+}
+
+// fix inserts the header after the prologue using the dominant line ending if it is required or updates the years
+// of an existing header which end before the given year
+func (h licenseHeader) fix(content string, year int, required bool) string {
+	prologue := licensePrologueRegex.FindString(content)
+	rest := content[len(prologue):]
+	loc := h.regex.FindStringSubmatchIndex(rest)
+	if loc == nil {
+		if !required {
+			return content
+		}
+		// the header uses the line ending most lines of the file end with
+		eol := lineEnding(strings.Count(content, "\r\n")*2 > strings.Count(content, "\n"))
+		text := strings.ReplaceAll(h.text, "\n", eol)
+		if strings.TrimSpace(rest) == "" {
+			return prologue + text + eol
+		}
+		return prologue + text + eol + eol + rest
+	}
+	var b strings.Builder
+	b.WriteString(prologue)
+	last := 0
+	for g := 2; g+1 < len(loc); g += 2 {
+		b.WriteString(rest[last:loc[g]])
+		b.WriteString(updateYearRange(rest[loc[g]:loc[g+1]], year))
+		last = loc[g+1]
+	}
+	b.WriteString(rest[last:])
+	return b.String()
+}
+
+// updateYearRange extends a year or year range to end with the given year, e.g. '2019' becomes '2019-2024'
+func updateYearRange(found string, year int) string {
+	parts := strings.Split(found, "-")
+	first := strings.TrimSpace(parts[0])
+	last, _ := strconv.Atoi(strings.TrimSpace(parts[len(parts)-1]))
+	if last >= year {
+		return found
+	}
+	return fmt.Sprintf("%s-%d", first, year)
+}
@@ -0,0 +1,114 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLicenseTemplate = "Copyright {year} {owner}\n\nLicensed under the MIT license"
+
+func TestLicenseHeader(t *testing.T) {
+	year := time.Now().Year()
+	goHeader := fmt.Sprintf("// Copyright %d ACME\n//\n// Licensed under the MIT license\n", year)
+	headerTests := []struct {
+		name              string
+		style             commentStyle
+		content           string
+		year              int
+		required          bool
+		locationsExpected []string
+		fixedExpected     string
+	}{
+		{"present", lineCommentStyle, goHeader + "\npackage main\n", 0, true, nil, goHeader + "\npackage main\n"},
+		{"missing", lineCommentStyle, "package main\n", 0, true, []string{"f:1:1"}, goHeader + "\npackage main\n"},
+		{"missing in empty file", lineCommentStyle, "", 0, true, []string{"f:1:1"}, goHeader},
+		{"missing but not required", lineCommentStyle, "package main\n", year, false, nil, "package main\n"},
+		{"other year", lineCommentStyle, "// Copyright 2019 ACME\n//\n// Licensed under the MIT license\n", 0, true, nil,
+			"// Copyright 2019 ACME\n//\n// Licensed under the MIT license\n"},
+		{"outdated year", lineCommentStyle, "// Copyright 2019 ACME\n//\n// Licensed under the MIT license\n", year, false, []string{"f:1:14"},
+			fmt.Sprintf("// Copyright 2019-%d ACME\n//\n// Licensed under the MIT license\n", year)},
+		{"outdated year range", hashCommentStyle, "# Copyright 2019-2020 ACME\n#\n# Licensed under the MIT license\n", year, false, []string{"f:1:13"},
+			fmt.Sprintf("# Copyright 2019-%d ACME\n#\n# Licensed under the MIT license\n", year)},
+		{"shebang", hashCommentStyle, "#!/bin/sh\necho hi\n", 0, true, []string{"f:1:1"},
+			fmt.Sprintf("#!/bin/sh\n# Copyright %d ACME\n#\n# Licensed under the MIT license\n\necho hi\n", year)},
+		{"after build constraints", lineCommentStyle, "//go:build linux\n// +build linux\n\n" + goHeader + "\npackage main\n", 0, true, nil,
+			"//go:build linux\n// +build linux\n\n" + goHeader + "\npackage main\n"},
+		{"missing after build constraints", lineCommentStyle, "//go:build linux\n\npackage main\n", 0, true, []string{"f:1:1"},
+			"//go:build linux\n\n" + goHeader + "\npackage main\n"},
+		{"present with crlf", hashCommentStyle, "# Copyright 2019 ACME\r\n#\r\n# Licensed under the MIT license\r\n", 0, true, nil,
+			"# Copyright 2019 ACME\r\n#\r\n# Licensed under the MIT license\r\n"},
+		{"crlf", hashCommentStyle, "echo hi\r\necho ho\r\n", 0, true, []string{"f:1:1"},
+			fmt.Sprintf("# Copyright %d ACME\r\n#\r\n# Licensed under the MIT license\r\n\r\necho hi\r\necho ho\r\n", year)},
+		{"block comment", xmlCommentStyle, "<?xml version=\"1.0\"?>\n<a/>\n", 0, true, []string{"f:1:1"},
+			fmt.Sprintf("<?xml version=\"1.0\"?>\n<!--\n  Copyright %d ACME\n\n  Licensed under the MIT license\n-->\n\n<a/>\n", year)},
+	}
+	for _, tt := range headerTests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newLicenseHeader(testLicenseTemplate, "ACME", year, tt.style)
+			var locations []string
+			for _, f := range h.check("f", tt.content, tt.year, tt.required) {
+				locations = append(locations, strings.SplitN(f.String(), ": ", 2)[0])
+			}
+			assert.Equal(t, tt.locationsExpected, locations, "expected problems should be reported")
+			assert.Equal(t, tt.fixedExpected, h.fix(tt.content, tt.year, tt.required), "content should be fixed")
+		})
+	}
+}
+
+func TestCommentStyles(t *testing.T) {
+	styles, err := commentStyles(".PHP=//\n.ftl=<#--| |-->")
+	assert.NoError(t, err, "styles should be valid")
+	assert.Equal(t, lineCommentStyle, styles[".php"], "line comment style should be added")
+	assert.Equal(t, commentStyle{start: "<#--", prefix: " ", end: "-->"}, styles[".ftl"], "block comment style should be added")
+	assert.Equal(t, lineCommentStyle, styles[".go"], "default styles should be kept")
+	_, err = commentStyles("php=//")
+	assert.Error(t, err, "extensions without dot should be rejected")
+	_, err = commentStyles(".php=/*|*/")
+	assert.Error(t, err, "incomplete block styles should be rejected")
+}
+
+func TestLicenseHeaderFix(t *testing.T) {
+	year := time.Now().Year()
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("old.go", "// Copyright 2019 ACME\n\npackage main\n")
+	tr.WriteFile("legacy.go", "package main\n")
+	tr.AddAll()
+	tr.Commit("initial")
+	tr.WriteFile("LICENSE_HEADER", "Copyright {year} {owner}\n")
+	tr.WriteFile("old.go", "// Copyright 2019 ACME\n\npackage main\n\nvar a = 1\n")
+	tr.WriteFile("legacy.go", "package main\n\nvar b = 1\n")
+	tr.WriteFile("new.go", "package main\n")
+	tr.WriteFile("README.md", "no comment style\n")
+	tr.AddAll()
+
+	lh, _ := Get("license-header")
+	vars := map[string]string{"LICENSE_HEADER_TEMPLATE_FILE": "LICENSE_HEADER", "LICENSE_HEADER_OWNER": "ACME", "LICENSE_HEADER_UPDATE_YEAR": "true"}
+	in := testInput(git.HookPreCommit, vars, nil)
+	in.Repo = git.NewRepository(tr.AbsDir())
+	res := lh.Run(context.Background(), in)
+	assert.Equal(t, StatusFail, res.Status, "missing and outdated headers should fail the hook: %s", res)
+	var files []string
+	for _, f := range res.Findings {
+		files = append(files, f.File)
+	}
+	assert.ElementsMatch(t, []string{"old.go", "new.go"}, files, "only added files and outdated headers should be reported")
+
+	vars["LICENSE_HEADER_FIX"] = "true"
+	res = lh.Run(context.Background(), in)
+	assert.Equal(t, StatusPass, res.Status, "headers should be fixed: %s", res)
+	added, _ := tr.Command("show", ":new.go")
+	assert.Equal(t, fmt.Sprintf("// Copyright %d ACME\n\npackage main\n", year), added, "header should be inserted")
+	modified, _ := os.ReadFile(filepath.Join(tr.AbsDir(), "old.go"))
+	assert.Equal(t, fmt.Sprintf("// Copyright 2019-%d ACME\n\npackage main\n\nvar a = 1\n", year), string(modified), "year should be updated")
+
+	res = lh.Run(context.Background(), testInput(git.HookPreCommit, map[string]string{}, nil))
+	assert.Equal(t, StatusError, res.Status, "missing template should be reported")
+}
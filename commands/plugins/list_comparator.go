@@ -1,70 +1,255 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
+	"path"
+	"regexp"
 	"strings"
 )
 
 const (
-	varListA                     = "LIST_COMPARATOR_LIST_A"
-	varListB                     = "LIST_COMPARATOR_LIST_B"
-	varListOperator              = "LIST_COMPARATOR_OPERATION"
+	varListA                  = "LIST_COMPARATOR_LIST_A"
+	varListB                  = "LIST_COMPARATOR_LIST_B"
+	varListOperator           = "LIST_COMPARATOR_OPERATION"
+	varListComparatorBlocking = "LIST_COMPARATOR_BLOCKING"
+	varListComparatorFailOn   = "LIST_COMPARATOR_FAIL_ON"
+	// varListComparatorFailOnMatch is the deprecated alias of varListComparatorBlocking
 	varListComparatorFailOnMatch = "LIST_COMPARATOR_FAIL_ON_MATCH"
+	varListComparatorMatching    = "LIST_COMPARATOR_MATCHING"
+	varListComparatorSeparator   = "LIST_COMPARATOR_SEPARATOR"
 )
 
-var operations = []string{"intersect"}
+const (
+	operationIntersect           = "intersect"
+	operationDifference          = "difference"
+	operationSymmetricDifference = "symmetric-difference"
+	operationSubset              = "subset"
+	operationSuperset            = "superset"
+	operationEqual               = "equal"
+
+	matchingExact = "exact"
+	matchingGlob  = "glob"
+	matchingRegex = "regex"
+
+	failOnMatch   = "match"
+	failOnNoMatch = "no-match"
+
+	separatorWhitespace = "whitespace"
+)
+
+var operations = []string{operationIntersect, operationDifference, operationSymmetricDifference, operationSubset, operationSuperset, operationEqual}
+var matchings = []string{matchingExact, matchingGlob, matchingRegex}
+
+// separatorAliases maps readable names to separators which are hard to express within a YAML config
+var separatorAliases = map[string]string{
+	"newline": "\n",
+	"nul":     "\x00",
+	"tab":     "\t",
+	"comma":   ",",
+	`\n`:      "\n",
+	`\0`:      "\x00",
+	`\t`:      "\t",
+}
 
 type ListComparator struct{}
 
+// elementMatcher reports whether an element of list A matches an element of list B
+type elementMatcher func(a, b string) bool
+
 func (lc ListComparator) ID() string {
 	return "list-comparator"
 }
 
-func (lc ListComparator) Run(workingDir string, hook string, vars map[string]string, args []string) (bool, error) {
-	listAStr, err := extractStringVar(varListA, vars, false)
-	if err != nil {
-		return true, err
+func (lc ListComparator) Description() string {
+	return "Compares two lists and fails or warns depending on whether the comparison matches."
+}
+
+func (lc ListComparator) Hooks() []string {
+	return nil
+}
+
+func (lc ListComparator) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varListA, Type: VarTypeList, Help: "first list"},
+		{Name: varListB, Type: VarTypeList, Help: "second list, holds the patterns in case glob or regex matching is used"},
+		{Name: varListOperator, Type: VarTypeString, Required: true, Help: "comparison operation, one of: " + strings.Join(operations, ", ")},
+		{Name: varListComparatorFailOn, Type: VarTypeString, Default: failOnMatch,
+			Help: "whether the comparison is reported in case it does 'match' or does 'no-match'. " + varListComparatorBlocking + " decides whether a report fails or warns"},
+		{Name: varListComparatorBlocking, Type: VarTypeBool, Default: "false", Help: "fail instead of warn in case the comparison is reported"},
+		{Name: varListComparatorFailOnMatch, Type: VarTypeBool, Default: "false",
+			Help: "deprecated alias of " + varListComparatorBlocking + " which can not be combined with " + varListComparatorFailOn + " 'no-match'"},
+		{Name: varListComparatorMatching, Type: VarTypeString, Default: matchingExact, Help: "how elements are compared, one of: " + strings.Join(matchings, ", ")},
+		{Name: varListComparatorSeparator, Type: VarTypeString, Default: separatorWhitespace,
+			Help: "separator of the list elements, either a string or one of: whitespace, newline, nul, tab, comma"},
 	}
+}
 
-	listBStr, err := extractStringVar(varListB, vars, false)
-	if err != nil {
-		return true, err
+// listComparatorBlocking returns whether a reported comparison fails. The deprecated alias is only taken into account
+// in case the blocking variable is absent and the comparison is reported on matches since its name contradicts
+// reports on mismatches.
+func listComparatorBlocking(vars Vars, failOn string) (bool, error) {
+	if _, ok := vars[varListComparatorBlocking]; ok {
+		return vars.Bool(varListComparatorBlocking, false)
 	}
-	listA := strings.Split(strings.TrimSpace(listAStr), " ")
-	listB := strings.Split(strings.TrimSpace(listBStr), " ")
-	if listA[0] == "" || listB[0] == "" {
+	if _, ok := vars[varListComparatorFailOnMatch]; !ok {
 		return false, nil
 	}
+	if failOn != failOnMatch {
+		return false, fmt.Errorf("deprecated '%s' contradicts '%s' '%s', use '%s' instead", varListComparatorFailOnMatch, varListComparatorFailOn, failOn, varListComparatorBlocking)
+	}
+	return vars.Bool(varListComparatorFailOnMatch, false)
+}
 
-	operation, err := extractStringVar(varListOperator, vars, true)
+func (lc ListComparator) Run(ctx context.Context, in RunInput) Result {
+	operation, err := in.Vars.String(varListOperator, true)
 	if err != nil {
-		return true, err
+		return Errorf("%s", err)
 	}
 	if !contains(operations, operation) {
-		return true, fmt.Errorf("list-comparator does not support operation '%s'", operation)
+		return Errorf("list-comparator does not support operation '%s'", operation)
 	}
-
-	failOnMatch, err := extractBoolVar(varListComparatorFailOnMatch, vars, false)
+	failOn, err := in.Vars.String(varListComparatorFailOn, false)
 	if err != nil {
-		return true, err
+		return Errorf("%s", err)
 	}
-	if diff := compare(listA, listB, operation); len(diff) > 0 {
-		return failOnMatch, fmt.Errorf("elements which matched the comparison '%s'", strings.Join(diff, ","))
+	if failOn == "" {
+		failOn = failOnMatch
 	}
-	return false, nil
+	if failOn != failOnMatch && failOn != failOnNoMatch {
+		return Errorf("list-comparator can only fail on '%s' or '%s' but not on '%s'", failOnMatch, failOnNoMatch, failOn)
+	}
+	blocking, err := listComparatorBlocking(in.Vars, failOn)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	// separators consisting of whitespace only must not be trimmed
+	separator := in.Vars[varListComparatorSeparator]
+	matching, err := in.Vars.String(varListComparatorMatching, false)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	listA := splitList(in.Vars[varListA], separator)
+	listB := splitList(in.Vars[varListB], separator)
+	matcher, err := newElementMatcher(matching, listB)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+
+	matches, elements := compare(listA, listB, operation, matcher)
+	findings := make([]Finding, len(elements))
+	for i, el := range elements {
+		findings[i] = Finding{Message: el}
+	}
+	switch {
+	case matches && failOn == failOnMatch:
+		if isRelation(operation) {
+			return Fail(blocking, fmt.Sprintf("lists matched the comparison '%s'", operation))
+		}
+		return Fail(blocking, fmt.Sprintf("elements which matched the comparison '%s':", operation), findings...)
+	case !matches && failOn == failOnNoMatch:
+		if isRelation(operation) {
+			return Fail(blocking, fmt.Sprintf("elements which violated the comparison '%s':", operation), findings...)
+		}
+		return Fail(blocking, fmt.Sprintf("no elements matched the comparison '%s'", operation))
+	}
+	return Pass()
 }
 
-func compare(a, b []string, operation string) []string {
-	var diff []string
-	switch operation {
-	case "intersect":
-		for _, el := range a {
-			if contains(b, el) {
-				diff = append(diff, el)
+// splitList splits the list by the separator. Empty elements are omitted.
+func splitList(list string, separator string) []string {
+	if separator == "" || strings.TrimSpace(separator) == separatorWhitespace {
+		return strings.Fields(list)
+	}
+	if alias, ok := separatorAliases[strings.TrimSpace(separator)]; ok {
+		separator = alias
+	}
+	var elements []string
+	for _, el := range strings.Split(list, separator) {
+		if strings.TrimSpace(el) != "" {
+			elements = append(elements, el)
+		}
+	}
+	return elements
+}
+
+// newElementMatcher returns a matcher for the matching mode. The elements of list B are used as patterns.
+func newElementMatcher(matching string, patterns []string) (elementMatcher, error) {
+	switch matching {
+	case "", matchingExact:
+		return func(a, b string) bool { return a == b }, nil
+	case matchingGlob:
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("provided glob '%s' is malformed", p)
+			}
+		}
+		return func(a, b string) bool {
+			ok, _ := path.Match(b, a)
+			return ok
+		}, nil
+	case matchingRegex:
+		compiled := map[string]*regexp.Regexp{}
+		for _, p := range patterns {
+			r, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("provided pattern '%s' can not be used as a regexp", p)
 			}
+			compiled[p] = r
 		}
+		return func(a, b string) bool { return compiled[b].MatchString(a) }, nil
 	}
-	return diff
+	return nil, fmt.Errorf("list-comparator does not support matching '%s'", matching)
+}
+
+// compare returns whether the comparison matches alongside the relevant elements. For set operations these are
+// the elements of the resulting set, for relations (subset, superset, equal) the elements violating the relation.
+func compare(a, b []string, operation string, match elementMatcher) (bool, []string) {
+	// elements of A not matching any element of B and vice versa
+	var onlyA, onlyB, both []string
+	for _, elA := range a {
+		if matchesAny(elA, b, match) {
+			both = append(both, elA)
+		} else {
+			onlyA = append(onlyA, elA)
+		}
+	}
+	for _, elB := range b {
+		if !matchesAny(elB, a, func(x, y string) bool { return match(y, x) }) {
+			onlyB = append(onlyB, elB)
+		}
+	}
+	switch operation {
+	case operationIntersect:
+		return len(both) > 0, both
+	case operationDifference:
+		return len(onlyA) > 0, onlyA
+	case operationSymmetricDifference:
+		diff := append(onlyA, onlyB...)
+		return len(diff) > 0, diff
+	case operationSubset:
+		return len(onlyA) == 0, onlyA
+	case operationSuperset:
+		return len(onlyB) == 0, onlyB
+	case operationEqual:
+		diff := append(onlyA, onlyB...)
+		return len(diff) == 0, diff
+	}
+	return false, nil
+}
+
+func matchesAny(el string, list []string, match elementMatcher) bool {
+	for _, other := range list {
+		if match(el, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRelation returns whether the operation describes a relation between both lists instead of resulting in a set
+func isRelation(operation string) bool {
+	return operation == operationSubset || operation == operationSuperset || operation == operationEqual
 }
 
 func contains(hs []string, n string) bool {
@@ -1,19 +1,19 @@
 package plugins
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
 func TestListComparator(t *testing.T) {
 	listComparatorTests := []struct {
-		name         string
-		listA        string
-		listB        string
-		operation    string
-		failOnMatch  string
-		exitExpected bool
-		errExpected  bool
+		name           string
+		listA          string
+		listB          string
+		operation      string
+		failOnMatch    string
+		statusExpected Status
 	}{
 		{
 			"lists intersect fail",
@@ -21,8 +21,7 @@ func TestListComparator(t *testing.T) {
 			"c d e",
 			"intersect",
 			"true",
-			true,
-			true,
+			StatusFail,
 		},
 		{
 			"lists intersect pass",
@@ -30,8 +29,7 @@ func TestListComparator(t *testing.T) {
 			"c d e",
 			"intersect",
 			"false",
-			false,
-			true,
+			StatusWarn,
 		},
 		{
 			"lists no intersection",
@@ -39,8 +37,7 @@ func TestListComparator(t *testing.T) {
 			"d e",
 			"intersect",
 			"true",
-			false,
-			false,
+			StatusPass,
 		},
 		{
 			"invalid operation",
@@ -48,8 +45,7 @@ func TestListComparator(t *testing.T) {
 			"d e",
 			"foo",
 			"true",
-			true,
-			true,
+			StatusError,
 		},
 		{
 			"empty lists",
@@ -57,8 +53,7 @@ func TestListComparator(t *testing.T) {
 			"    ",
 			"intersect",
 			"true",
-			false,
-			false,
+			StatusPass,
 		},
 	}
 	lc, _ := Get("list-comparator")
@@ -70,9 +65,153 @@ func TestListComparator(t *testing.T) {
 				"LIST_COMPARATOR_OPERATION":     tt.operation,
 				"LIST_COMPARATOR_FAIL_ON_MATCH": tt.failOnMatch,
 			}
-			ok, err := lc.Run("", "pre-commit", vars, nil)
-			assert.Equal(t, tt.exitExpected, ok, "expected bool does not match executed plugin result")
-			assert.Equal(t, tt.errExpected, err != nil, "error expectation and result does not match")
+			res := lc.Run(context.Background(), testInput("pre-commit", vars, nil))
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match executed plugin result")
+		})
+	}
+}
+
+func TestListComparatorOperations(t *testing.T) {
+	operationTests := []struct {
+		name             string
+		vars             map[string]string
+		statusExpected   Status
+		findingsExpected []string
+	}{
+		{
+			"difference",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a b c", "LIST_COMPARATOR_LIST_B": "b", "LIST_COMPARATOR_OPERATION": "difference"},
+			StatusWarn,
+			[]string{"a", "c"},
+		},
+		{
+			"symmetric difference",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a b", "LIST_COMPARATOR_LIST_B": "b c", "LIST_COMPARATOR_OPERATION": "symmetric-difference"},
+			StatusWarn,
+			[]string{"a", "c"},
+		},
+		{
+			"subset violated",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a d", "LIST_COMPARATOR_LIST_B": "a b c", "LIST_COMPARATOR_OPERATION": "subset",
+				"LIST_COMPARATOR_FAIL_ON": "no-match", "LIST_COMPARATOR_BLOCKING": "true"},
+			StatusFail,
+			[]string{"d"},
+		},
+		{
+			"deprecated blocking alias on mismatch",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a d", "LIST_COMPARATOR_LIST_B": "a b c", "LIST_COMPARATOR_OPERATION": "subset",
+				"LIST_COMPARATOR_FAIL_ON": "no-match", "LIST_COMPARATOR_FAIL_ON_MATCH": "true"},
+			StatusError,
+			nil,
+		},
+		{
+			"blocking overrides deprecated alias",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a b", "LIST_COMPARATOR_LIST_B": "b", "LIST_COMPARATOR_OPERATION": "intersect",
+				"LIST_COMPARATOR_BLOCKING": "false", "LIST_COMPARATOR_FAIL_ON_MATCH": "true"},
+			StatusWarn,
+			[]string{"b"},
+		},
+		{
+			"subset holds",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a b", "LIST_COMPARATOR_LIST_B": "a b c", "LIST_COMPARATOR_OPERATION": "subset",
+				"LIST_COMPARATOR_FAIL_ON": "no-match"},
+			StatusPass,
+			nil,
+		},
+		{
+			"superset matches",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a b c", "LIST_COMPARATOR_LIST_B": "a b", "LIST_COMPARATOR_OPERATION": "superset"},
+			StatusWarn,
+			nil,
+		},
+		{
+			"equal violated",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a b", "LIST_COMPARATOR_LIST_B": "b c", "LIST_COMPARATOR_OPERATION": "equal",
+				"LIST_COMPARATOR_FAIL_ON": "no-match"},
+			StatusWarn,
+			[]string{"a", "c"},
+		},
+		{
+			"intersect without match",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a", "LIST_COMPARATOR_LIST_B": "b", "LIST_COMPARATOR_OPERATION": "intersect",
+				"LIST_COMPARATOR_FAIL_ON": "no-match"},
+			StatusWarn,
+			nil,
+		},
+		{
+			"glob matching",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "go.mod main.go docs/readme.md", "LIST_COMPARATOR_LIST_B": "*.go go.*",
+				"LIST_COMPARATOR_OPERATION": "intersect", "LIST_COMPARATOR_MATCHING": "glob"},
+			StatusWarn,
+			[]string{"go.mod", "main.go"},
+		},
+		{
+			"regex matching",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "go.mod main.go", "LIST_COMPARATOR_LIST_B": `\.go$`,
+				"LIST_COMPARATOR_OPERATION": "difference", "LIST_COMPARATOR_MATCHING": "regex"},
+			StatusWarn,
+			[]string{"go.mod"},
+		},
+		{
+			"nul separator",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "my file.go\x00other.go\x00", "LIST_COMPARATOR_LIST_B": "my file.go",
+				"LIST_COMPARATOR_OPERATION": "intersect", "LIST_COMPARATOR_SEPARATOR": "nul"},
+			StatusWarn,
+			[]string{"my file.go"},
+		},
+		{
+			"newline separator",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a b\nc", "LIST_COMPARATOR_LIST_B": "a b",
+				"LIST_COMPARATOR_OPERATION": "difference", "LIST_COMPARATOR_SEPARATOR": `\n`},
+			StatusWarn,
+			[]string{"c"},
+		},
+		{
+			"literal newline separator",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a b\nc", "LIST_COMPARATOR_LIST_B": "a",
+				"LIST_COMPARATOR_OPERATION": "difference", "LIST_COMPARATOR_SEPARATOR": "\n"},
+			StatusWarn,
+			[]string{"a b", "c"},
+		},
+		{
+			"literal tab separator",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a b\tc", "LIST_COMPARATOR_LIST_B": "a",
+				"LIST_COMPARATOR_OPERATION": "difference", "LIST_COMPARATOR_SEPARATOR": "\t"},
+			StatusWarn,
+			[]string{"a b", "c"},
+		},
+		{
+			"space separator",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a\tb c", "LIST_COMPARATOR_LIST_B": "a",
+				"LIST_COMPARATOR_OPERATION": "difference", "LIST_COMPARATOR_SEPARATOR": " "},
+			StatusWarn,
+			[]string{"a\tb", "c"},
+		},
+		{
+			"invalid matching",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a", "LIST_COMPARATOR_LIST_B": "a", "LIST_COMPARATOR_OPERATION": "intersect",
+				"LIST_COMPARATOR_MATCHING": "fuzzy"},
+			StatusError,
+			nil,
+		},
+		{
+			"invalid fail on",
+			map[string]string{"LIST_COMPARATOR_LIST_A": "a", "LIST_COMPARATOR_LIST_B": "a", "LIST_COMPARATOR_OPERATION": "intersect",
+				"LIST_COMPARATOR_FAIL_ON": "always"},
+			StatusError,
+			nil,
+		},
+	}
+	lc, _ := Get("list-comparator")
+	for _, tt := range operationTests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := lc.Run(context.Background(), testInput("pre-commit", tt.vars, nil))
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match executed plugin result: %s", res)
+			var findings []string
+			for _, f := range res.Findings {
+				findings = append(findings, f.Message)
+			}
+			assert.Equal(t, tt.findingsExpected, findings, "expected elements do not match reported elements")
 		})
 	}
 }
@@ -0,0 +1,292 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	varPathPolicyChecks         = "PATH_POLICY_CHECKS"
+	varPathPolicyReservedNames  = "PATH_POLICY_RESERVED_NAMES"
+	varPathPolicyForbiddenChars = "PATH_POLICY_FORBIDDEN_CHARS"
+	varPathPolicyMaxLength      = "PATH_POLICY_MAX_LENGTH"
+	varPathPolicyRules          = "PATH_POLICY_RULES"
+)
+
+const (
+	checkCaseCollisions   = "case-collisions"
+	checkReservedNames    = "reserved-names"
+	checkForbiddenChars   = "forbidden-characters"
+	checkMaxLength        = "max-length"
+	defaultForbiddenChars = `<>:"\|?*`
+	defaultMaxPathLength  = 260
+)
+
+var pathPolicyChecks = []string{checkCaseCollisions, checkReservedNames, checkForbiddenChars, checkMaxLength}
+
+// defaultReservedNames are the device names Windows does not allow as file names regardless of their extension
+var defaultReservedNames = []string{"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9"}
+
+// PathPolicy validates the paths of staged added files. Staged blobs are determined without rename detection, hence
+// renamed and copied files are validated as additions as well.
+type PathPolicy struct{}
+
+// pathRule holds the globs files within a directory have to match (allow) or must not match (deny)
+type pathRule struct {
+	dir   string
+	allow []string
+	deny  []string
+}
+
+func (pp PathPolicy) ID() string {
+	return "path-policy"
+}
+
+func (pp PathPolicy) Description() string {
+	return "Validates paths of staged added and renamed files against case collisions, reserved names, forbidden characters, their length and per directory globs."
+}
+
+func (pp PathPolicy) Hooks() []string {
+	return []string{git.HookPreCommit}
+}
+
+func (pp PathPolicy) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varPathPolicyChecks, Type: VarTypeList, Default: strings.Join(pathPolicyChecks, " "), Help: "space separated list of enabled checks"},
+		{Name: varPathPolicyReservedNames, Type: VarTypeList, Default: strings.Join(defaultReservedNames, " "),
+			Help: "space separated list of names which are not allowed for any path element regardless of case and extension"},
+		{Name: varPathPolicyForbiddenChars, Type: VarTypeString, Default: defaultForbiddenChars,
+			Help: "characters which are not allowed within paths. Control characters and trailing dots or spaces of path elements are always forbidden"},
+		{Name: varPathPolicyMaxLength, Type: VarTypeInt, Default: fmt.Sprint(defaultMaxPathLength), Help: "maximum amount of characters of a path relative to the repository"},
+		{Name: varPathPolicyRules, Type: VarTypeString,
+			Help: "newline separated list of 'dir=glob...' entries. Files within the directory have to match one of the globs, globs prefixed with '!' must not match. The rule of the most specific directory applies"},
+	}
+}
+
+func (pp PathPolicy) Run(ctx context.Context, in RunInput) Result {
+	if in.Hook != git.HookPreCommit {
+		return hookUnsupported(in.Hook, pp.ID())
+	}
+	checks := in.Vars.List(varPathPolicyChecks)
+	if _, ok := in.Vars[varPathPolicyChecks]; !ok {
+		checks = pathPolicyChecks
+	}
+	for _, c := range checks {
+		if !contains(pathPolicyChecks, c) {
+			return Errorf("unknown check '%s'. Supported checks are '%s'", c, strings.Join(pathPolicyChecks, "', '"))
+		}
+	}
+	reserved := in.Vars.List(varPathPolicyReservedNames)
+	if _, ok := in.Vars[varPathPolicyReservedNames]; !ok {
+		reserved = defaultReservedNames
+	}
+	forbidden := defaultForbiddenChars
+	if v, ok := in.Vars[varPathPolicyForbiddenChars]; ok {
+		forbidden = v
+	}
+	maxLength := defaultMaxPathLength
+	if _, ok := in.Vars[varPathPolicyMaxLength]; ok {
+		var err error
+		if maxLength, err = in.Vars.Int(varPathPolicyMaxLength, false); err != nil {
+			return Errorf("%s", err)
+		}
+	}
+	rules, err := pathRules(in.Vars[varPathPolicyRules])
+	if err != nil {
+		return Errorf("variable '%s' is invalid: %s", varPathPolicyRules, err)
+	}
+
+	staged, err := in.Repo.StagedBlobs()
+	if err != nil {
+		return Errorf("could not determine staged files: %+v", err)
+	}
+	var added []string
+	for _, b := range staged {
+		if b.Status == "A" {
+			added = append(added, b.Path)
+		}
+	}
+	if len(added) == 0 {
+		return Pass()
+	}
+	var variants map[string][]string
+	if contains(checks, checkCaseCollisions) {
+		indexed, err := in.Repo.IndexPaths()
+		if err != nil {
+			return Errorf("could not determine indexed files: %+v", err)
+		}
+		variants = caseVariants(indexed)
+	}
+
+	var findings []Finding
+	for _, p := range added {
+		var problems []string
+		if variants != nil {
+			problems = append(problems, caseCollisions(p, variants)...)
+		}
+		if contains(checks, checkReservedNames) {
+			problems = append(problems, reservedNames(p, reserved)...)
+		}
+		if contains(checks, checkForbiddenChars) {
+			problems = append(problems, forbiddenChars(p, forbidden)...)
+		}
+		if contains(checks, checkMaxLength) && maxLength > 0 {
+			if l := utf8.RuneCountInString(p); l > maxLength {
+				problems = append(problems, fmt.Sprintf("path has %d characters, the maximum is %d", l, maxLength))
+			}
+		}
+		if problem := violatedPathRule(p, rules); problem != "" {
+			problems = append(problems, problem)
+		}
+		for _, problem := range problems {
+			findings = append(findings, Finding{File: p, Message: problem})
+		}
+	}
+	if len(findings) > 0 {
+		return Fail(true, "staged files have paths which violate the path policy", findings...)
+	}
+	return Pass()
+}
+
+// caseVariants maps the lower case variant of every file and directory path to their actual spellings
+func caseVariants(paths []string) map[string][]string {
+	variants := map[string][]string{}
+	for _, p := range paths {
+		for _, prefix := range pathPrefixes(p) {
+			lower := strings.ToLower(prefix)
+			if !contains(variants[lower], prefix) {
+				variants[lower] = append(variants[lower], prefix)
+			}
+		}
+	}
+	return variants
+}
+
+// caseCollisions reports the first directory or the file of the path which only differs in case from an indexed one
+func caseCollisions(p string, variants map[string][]string) []string {
+	for _, prefix := range pathPrefixes(p) {
+		var others []string
+		for _, v := range variants[strings.ToLower(prefix)] {
+			if v != prefix {
+				others = append(others, v)
+			}
+		}
+		if len(others) > 0 {
+			sort.Strings(others)
+			return []string{fmt.Sprintf("'%s' only differs in case from '%s'", prefix, strings.Join(others, "', '"))}
+		}
+	}
+	return nil
+}
+
+// pathPrefixes returns the paths of all parent directories followed by the path itself, e.g. 'a', 'a/b', 'a/b/c'
+func pathPrefixes(p string) []string {
+	var prefixes []string
+	for i, c := range p {
+		if c == '/' {
+			prefixes = append(prefixes, p[:i])
+		}
+	}
+	return append(prefixes, p)
+}
+
+// reservedNames reports path elements whose name without extension is reserved
+func reservedNames(p string, reserved []string) []string {
+	var problems []string
+	for _, element := range strings.Split(p, "/") {
+		name := strings.TrimRight(strings.SplitN(element, ".", 2)[0], " ")
+		for _, r := range reserved {
+			if strings.EqualFold(name, r) {
+				problems = append(problems, fmt.Sprintf("'%s' uses the reserved name '%s'", element, r))
+			}
+		}
+	}
+	return problems
+}
+
+// forbiddenChars reports forbidden characters, control characters and path elements ending with a dot or space
+func forbiddenChars(p string, forbidden string) []string {
+	var problems []string
+	var found []string
+	for _, c := range p {
+		if c < 0x20 || c == 0x7f {
+			found = git.AppendUnique(found, fmt.Sprintf("%q", c))
+		} else if strings.ContainsRune(forbidden, c) {
+			found = git.AppendUnique(found, string(c))
+		}
+	}
+	if len(found) > 0 {
+		problems = append(problems, fmt.Sprintf("path contains the forbidden characters '%s'", strings.Join(found, "', '")))
+	}
+	for _, element := range strings.Split(p, "/") {
+		if strings.HasSuffix(element, ".") || strings.HasSuffix(element, " ") {
+			problems = append(problems, fmt.Sprintf("'%s' ends with a dot or space", element))
+		}
+	}
+	return problems
+}
+
+// pathRules parses the 'dir=glob...' entries. The directories '.' and '/' refer to the root of the repository.
+func pathRules(list string) ([]pathRule, error) {
+	var rules []pathRule
+	for _, line := range strings.Split(list, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || len(strings.Fields(parts[1])) == 0 {
+			return nil, fmt.Errorf("rule '%s' is malformed, expected 'dir=glob...'", line)
+		}
+		r := pathRule{dir: strings.Trim(strings.TrimSpace(parts[0]), "/")}
+		if r.dir == "." {
+			r.dir = ""
+		}
+		for _, glob := range strings.Fields(parts[1]) {
+			deny := strings.HasPrefix(glob, "!")
+			glob = strings.TrimPrefix(glob, "!")
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("provided glob '%s' is malformed", glob)
+			}
+			if deny {
+				r.deny = append(r.deny, glob)
+			} else {
+				r.allow = append(r.allow, glob)
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// violatedPathRule applies the rule of the most specific directory containing the path. The globs are matched
+// against the path relative to that directory.
+func violatedPathRule(p string, rules []pathRule) string {
+	var rule *pathRule
+	for i, r := range rules {
+		if (r.dir == "" || strings.HasPrefix(p, r.dir+"/")) && (rule == nil || len(r.dir) > len(rule.dir)) {
+			rule = &rules[i]
+		}
+	}
+	if rule == nil {
+		return ""
+	}
+	dir := rule.dir
+	if dir == "" {
+		dir = "."
+	}
+	rel := strings.TrimPrefix(p, rule.dir+"/")
+	if matchesAnyGlob(rule.deny, rel) {
+		return fmt.Sprintf("files matching '%s' are not allowed within '%s'", strings.Join(rule.deny, "', '"), dir)
+	}
+	if len(rule.allow) > 0 && !matchesAnyGlob(rule.allow, rel) {
+		return fmt.Sprintf("only files matching '%s' are allowed within '%s'", strings.Join(rule.allow, "', '"), dir)
+	}
+	return ""
+}
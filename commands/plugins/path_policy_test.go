@@ -0,0 +1,76 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathPolicy(t *testing.T) {
+	policyTests := []struct {
+		name             string
+		vars             map[string]string
+		statusExpected   Status
+		findingsExpected []string
+	}{
+		{"default checks", map[string]string{}, StatusFail, []string{
+			"readme.md: 'readme.md' only differs in case from 'README.md'",
+			"Docs/guide.md: 'Docs' only differs in case from 'docs'",
+			"pkg/aux.go: 'aux.go' uses the reserved name 'AUX'",
+			"pkg/what?.go: path contains the forbidden characters '?'",
+			"trailing./a.go: 'trailing.' ends with a dot or space",
+			"lpt1.go: 'lpt1.go' uses the reserved name 'LPT1'",
+		}},
+		{"disabled checks", map[string]string{"PATH_POLICY_CHECKS": "max-length", "PATH_POLICY_MAX_LENGTH": "12"}, StatusFail, []string{
+			"Docs/guide.md: path has 13 characters, the maximum is 12",
+			"trailing./a.go: path has 14 characters, the maximum is 12",
+		}},
+		{"directory rules", map[string]string{"PATH_POLICY_CHECKS": "", "PATH_POLICY_RULES": ".=!*.exe\npkg/=*.go !aux.go\nDocs=*.txt"}, StatusFail, []string{
+			"pkg/aux.go: files matching 'aux.go' are not allowed within 'pkg'",
+			"Docs/guide.md: only files matching '*.txt' are allowed within 'Docs'",
+		}},
+		{"no violations", map[string]string{"PATH_POLICY_CHECKS": "", "PATH_POLICY_RULES": "pkg=*.go"}, StatusPass, nil},
+		{"unknown check", map[string]string{"PATH_POLICY_CHECKS": "unicode"}, StatusError, nil},
+		{"malformed rule", map[string]string{"PATH_POLICY_RULES": "pkg"}, StatusError, nil},
+	}
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("README.md", "readme")
+	tr.WriteFile("docs/index.md", "index")
+	tr.WriteFile("Pkg.go", "package pkg")
+	tr.WriteFile("printer.go", "package printer")
+	tr.AddAll()
+	tr.Commit("initial")
+	// modifications are not validated
+	tr.WriteFile("Pkg.go", "package pkg\n")
+	tr.WriteFile("readme.md", "readme")
+	tr.WriteFile("Docs/guide.md", "guide")
+	tr.WriteFile("pkg/aux.go", "package pkg")
+	tr.WriteFile("pkg/what?.go", "package pkg")
+	tr.WriteFile("trailing./a.go", "package a")
+	tr.AddAll()
+	// renames are validated as additions
+	_, _ = tr.Command("mv", "printer.go", "lpt1.go")
+
+	pp, _ := Get("path-policy")
+	for _, tt := range policyTests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := testInput(git.HookPreCommit, tt.vars, nil)
+			in.Repo = git.NewRepository(tr.AbsDir())
+			res := pp.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match result: %s", res)
+			var findings []string
+			for _, f := range res.Findings {
+				findings = append(findings, f.String())
+			}
+			assert.ElementsMatch(t, tt.findingsExpected, findings, "expected violations should be reported")
+		})
+	}
+}
+
+func TestPathPrefixes(t *testing.T) {
+	assert.Equal(t, []string{"a", "a/b", "a/b/c.go"}, pathPrefixes("a/b/c.go"), "all parent directories should be returned")
+	assert.Equal(t, []string{"a.go"}, pathPrefixes("a.go"), "files within the root should be returned as is")
+}
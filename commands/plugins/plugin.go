@@ -1,24 +1,72 @@
 package plugins
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/jenpet/giks/git"
 	"github.com/jenpet/giks/log"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
 	"regexp"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
 )
 
-// List holds all built-in giks plugins
-var List = []Plugin{
-	StringValidator{},
-	FileWatcher{},
-	ListComparator{},
+// registry holds all plugins compiled into the giks binary in the order of their registration
+var registry = struct {
+	sync.RWMutex
+	plugins []Plugin
+}{}
+
+func init() {
+	Register(StringValidator{})
+	Register(FileWatcher{})
+	Register(ListComparator{})
+	Register(ConventionalCommits{})
+	Register(SecretScanner{})
+	Register(BlobSize{})
+	Register(ProtectedBranch{})
+	Register(TicketID{})
+	Register(ConflictMarkers{})
+	Register(Whitespace{})
+	Register(GoFmt{})
+	Register(LicenseHeader{})
+	Register(PathPolicy{})
+	Register(DCO{})
+	Register(SyntaxValidator{})
+	Register(RefPolicy{})
+}
+
+// Register makes a plugin compiled into the giks binary available under its ID. It is meant to be called from an
+// init function and panics in case the ID is empty or already registered.
+func Register(p Plugin) {
+	registry.Lock()
+	defer registry.Unlock()
+	if p == nil || p.ID() == "" {
+		panic("plugins: Register plugin is nil or has an empty ID")
+	}
+	for _, registered := range registry.plugins {
+		if registered.ID() == p.ID() {
+			panic(fmt.Sprintf("plugins: Register called twice for plugin '%s'", p.ID()))
+		}
+	}
+	registry.plugins = append(registry.plugins, p)
+}
+
+// Registered returns all plugins compiled into the giks binary in the order of their registration
+func Registered() []Plugin {
+	registry.RLock()
+	defer registry.RUnlock()
+	return append([]Plugin{}, registry.plugins...)
 }
 
 // Get returns a plugin for the given name (identifier). In case none was found an error is returned.
 func Get(name string) (Plugin, error) {
-	for _, p := range List {
+	for _, p := range Registered() {
 		if p.ID() == name {
 			return p, nil
 		}
@@ -26,52 +74,230 @@ func Get(name string) (Plugin, error) {
 	return nil, fmt.Errorf("plugin '%s' not found", name)
 }
 
+// Lookup returns the plugin for the given name. Built-in plugins take precedence over script plugins located in the
+// ScriptPluginDirectory of the working directory which take precedence over external plugin executables. The latter
+// are searched within the plugin directory and the $PATH.
+func Lookup(name string, workingDir string, pluginDir string) (Plugin, error) {
+	if p, err := Get(name); err == nil {
+		return p, nil
+	}
+	if err := validatePluginName(name); err != nil {
+		return nil, err
+	}
+	p, err := FindScript(filepath.Join(workingDir, ScriptPluginDirectory), name)
+	if err == nil {
+		return p, nil
+	}
+	// a present but invalid script plugin should not be shadowed by an external one
+	if _, statErr := os.Stat(filepath.Join(workingDir, ScriptPluginDirectory, name)); statErr == nil {
+		return nil, err
+	}
+	return FindExternal(name, pluginDir)
+}
+
+// validatePluginName rejects names which are not a single path element and therefore could resolve script plugins or
+// external plugin executables outside of their directories
+func validatePluginName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("plugin name '%s' is invalid, it must not contain path separators or be '.' or '..'", name)
+	}
+	return nil
+}
+
+// Available returns all plugins which can be used within the given working and plugin directory ordered by their
+// precedence. Plugins which are shadowed by a plugin with the same ID and a higher precedence are omitted.
+func Available(workingDir string, pluginDir string) []Plugin {
+	available := Registered()
+	seen := map[string]bool{}
+	for _, p := range available {
+		seen[p.ID()] = true
+	}
+	scriptDir := filepath.Join(workingDir, ScriptPluginDirectory)
+	entries, _ := os.ReadDir(scriptDir)
+	for _, e := range entries {
+		if !e.IsDir() || seen[e.Name()] {
+			continue
+		}
+		p, err := FindScript(scriptDir, e.Name())
+		if err != nil {
+			log.Warnf("Ignoring script plugin '%s'. Error: %+v", e.Name(), err)
+			continue
+		}
+		seen[p.ID()] = true
+		available = append(available, p)
+	}
+	for _, dir := range append([]string{pluginDir}, filepath.SplitList(os.Getenv("PATH"))...) {
+		entries, _ = os.ReadDir(dir)
+		for _, e := range entries {
+			name := strings.TrimPrefix(e.Name(), externalPluginPrefix)
+			if name == e.Name() || seen[name] {
+				continue
+			}
+			if p, err := FindExternal(name, dir); err == nil {
+				seen[name] = true
+				available = append(available, p)
+			}
+		}
+	}
+	return available
+}
+
+// Source returns where a plugin originates from which is either 'built-in', 'script' or 'external'
+func Source(p Plugin) string {
+	switch p.(type) {
+	case ScriptPlugin:
+		return "script"
+	case ExternalPlugin:
+		return "external"
+	}
+	return "built-in"
+}
+
 // Plugin has to be implemented by all built-in plugins in order to be triggered correctly by the giks hook executor
 type Plugin interface {
-	// Run executes the plugin for a given workingDir and hook. The vars will contain all variables
-	// provided by giks, args are the arguments that were passed for the hook execution.
-	//
-	// The returned boolean indicates whether giks should exit after running the plugin providing relying on additional
-	// information given by the error.
-	Run(workingDir string, hook string, vars map[string]string, args []string) (bool, error)
+	// Run executes the plugin for the given input. The context is cancelled in case giks gets interrupted.
+	// The returned result indicates whether giks should continue with the next step.
+	Run(ctx context.Context, in RunInput) Result
 
 	// ID returns the name / identifier of the plugin which can be used within the configuration file
 	ID() string
 }
 
-func extractVar(key string, vars map[string]string, parseFunc func(val string) error, required bool) error {
-	if val, ok := vars[key]; ok {
-		if strings.TrimSpace(val) == "" && required {
-			return fmt.Errorf("variable '%s' is required but empty", key)
-		}
-		if err := parseFunc(val); err != nil {
-			if err != nil {
-				return fmt.Errorf("failed parsing '%s' variable", key)
-			}
-		}
-	} else if required {
-		return fmt.Errorf("variable '%s' is required but not set", key)
+// VarCommitSHA is provided by giks in case the commit-msg hook is applied to the message of an existing commit, e.g.
+// while checking all pushed commits. The message file then holds the message of this commit.
+const VarCommitSHA = "GIKS_COMMIT_SHA"
+
+// RunInput holds everything a plugin run can rely on
+type RunInput struct {
+	// Hook which is currently executed
+	Hook string
+	// Args are the arguments passed by git for the hook execution
+	Args []string
+	// Vars contain all variables provided by giks merged with the configured plugin variables
+	Vars Vars
+	// Repo is the repository the hook is executed for. Its directory is the working directory of the plugin.
+	Repo git.Repository
+	// Stdin provides the input passed by git to the hook
+	Stdin io.Reader
+	// Output is used for all messages the plugin wants to show to the user
+	Output io.Writer
+}
+
+// WorkingDir returns the directory the plugin is executed in
+func (in RunInput) WorkingDir() string {
+	return in.Repo.Dir
+}
+
+// Describer can be implemented by plugins in order to document themselves. The declared variables are used to
+// validate the plugin configuration before a hook gets executed.
+type Describer interface {
+	// Description returns a short human-readable summary of the plugin
+	Description() string
+	// Hooks returns the hooks supported by the plugin. An empty list indicates that all hooks are supported.
+	Hooks() []string
+	// Vars returns the schema of all variables the plugin evaluates
+	Vars() []VarSpec
+}
+
+const (
+	VarTypeString = "string"
+	VarTypeBool   = "bool"
+	VarTypeInt    = "int"
+	VarTypeFloat  = "float"
+	VarTypeRegexp = "regexp"
+	VarTypeList   = "list"
+)
+
+// VarSpec describes a single variable of a plugin
+type VarSpec struct {
+	Name     string
+	Type     string
+	Required bool
+	Default  string
+	Help     string
+}
+
+// SupportsHook returns whether the plugin can be executed within the hook. Plugins which do not declare their hooks
+// are assumed to support all hooks.
+func SupportsHook(p Plugin, hook string) bool {
+	d, ok := p.(Describer)
+	return !ok || len(d.Hooks()) == 0 || contains(d.Hooks(), hook)
+}
+
+// VarsError lists the problems of the configured variables of a plugin
+type VarsError struct {
+	Plugin string
+	// Invalid holds missing required variables and values which do not match the type of their variable
+	Invalid []string
+	// Unknown holds the names of variables the plugin does not evaluate
+	Unknown []string
+}
+
+func (ve *VarsError) Error() string {
+	problems := append([]string{}, ve.Invalid...)
+	for _, name := range ve.Unknown {
+		problems = append(problems, fmt.Sprintf("unknown variable '%s'", name))
 	}
-	return nil
+	return fmt.Sprintf("plugin '%s' is misconfigured: %s", ve.Plugin, strings.Join(problems, ", "))
 }
 
-func extractStringVar(key string, vars map[string]string, required bool) (string, error) {
-	var str string
-	err := extractVar(key, vars, func(val string) error {
-		str = val
+// ValidateVars checks the configured variables of a plugin against its schema and returns a *VarsError listing
+// missing required variables, values not matching the type of their variable and unknown variables. Values naming a
+// giks variable (e.g. 'GIKS_HOOK_TYPE') are only known at execution time, hence their type is not checked. Plugins
+// which do not implement Describer are not validated.
+func ValidateVars(p Plugin, vars map[string]string) error {
+	d, ok := p.(Describer)
+	if !ok {
 		return nil
-	}, required)
-	return str, err
+	}
+	verr := &VarsError{Plugin: p.ID()}
+	known := map[string]bool{}
+	for _, spec := range d.Vars() {
+		known[spec.Name] = true
+		val, ok := vars[spec.Name]
+		if spec.Required && spec.Default == "" && (!ok || strings.TrimSpace(val) == "") {
+			verr.Invalid = append(verr.Invalid, fmt.Sprintf("required variable '%s' is missing", spec.Name))
+			continue
+		}
+		if !ok || strings.HasPrefix(strings.TrimSpace(val), "GIKS_") {
+			continue
+		}
+		if err := validateVarType(spec, val); err != nil {
+			verr.Invalid = append(verr.Invalid, err.Error())
+		}
+	}
+	for name := range vars {
+		if !known[name] {
+			verr.Unknown = append(verr.Unknown, name)
+		}
+	}
+	sort.Strings(verr.Unknown)
+	if len(verr.Invalid) > 0 || len(verr.Unknown) > 0 {
+		return verr
+	}
+	return nil
 }
 
-func extractBoolVar(key string, vars map[string]string, required bool) (bool, error) {
-	var b bool
-	err := extractVar(key, vars, func(val string) error {
-		parsed, err := strconv.ParseBool(val)
-		b = parsed
-		return err
-	}, required)
-	return b, err
+// validateVarType checks that the value can be parsed according to the type of the variable
+func validateVarType(spec VarSpec, val string) error {
+	vars := Vars{spec.Name: val}
+	var err error
+	switch spec.Type {
+	case VarTypeBool:
+		_, err = vars.Bool(spec.Name, false)
+	case VarTypeInt:
+		_, err = vars.Int(spec.Name, false)
+	case VarTypeFloat:
+		_, err = vars.Float(spec.Name, false)
+	case VarTypeRegexp:
+		if _, rerr := regexp.Compile(val); rerr != nil {
+			err = fmt.Errorf("failed parsing '%s' variable", spec.Name)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s, expected type '%s' but got '%s'", err, spec.Type, val)
+	}
+	return nil
 }
 
 func matchString(input, pattern string) error {
@@ -88,7 +314,120 @@ func matchString(input, pattern string) error {
 	return nil
 }
 
-func hookUnsupported(hook string, plugin string) (bool, error) {
-	log.Warnf("hook '%s' not supported by plugin '%s'", hook, plugin)
-	return false, nil
+// matchGlob matches the file against the glob. Globs without a '/' are matched against the name of the file only.
+func matchGlob(pattern string, file string) bool {
+	if !strings.Contains(pattern, "/") {
+		file = path.Base(file)
+	}
+	ok, _ := path.Match(pattern, file)
+	return ok
+}
+
+// matchesAnyGlob returns whether the file matches one of the globs. Globs ending with '/' or '/**' match all files
+// within the directory.
+func matchesAnyGlob(patterns []string, file string) bool {
+	for _, p := range patterns {
+		if dir := strings.TrimSuffix(strings.TrimSuffix(p, "**"), "/"); dir != p && strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+		if matchGlob(p, file) {
+			return true
+		}
+	}
+	return false
+}
+
+func hookUnsupported(hook string, plugin string) Result {
+	return Skip("hook '%s' not supported by plugin '%s'", hook, plugin)
+}
+
+// refUpdates parses the ref updates passed via stdin, or the arguments of the update hook, and resolves the commits
+// they introduce
+func refUpdates(in RunInput) ([]git.RefUpdate, error) {
+	if in.Hook == git.HookUpdate {
+		u, err := git.ParseUpdateArgs(in.Args)
+		if err != nil {
+			return nil, err
+		}
+		return git.ResolveRefUpdates(in.WorkingDir(), in.Hook, []git.RefUpdate{u}), nil
+	}
+	if !git.HasRefUpdates(in.Hook) || in.Stdin == nil {
+		return nil, fmt.Errorf("hook '%s' does not provide ref updates", in.Hook)
+	}
+	b, err := io.ReadAll(in.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("could not read ref updates: %+v", err)
+	}
+	updates, err := git.ParseRefUpdates(in.Hook, string(b))
+	if err != nil {
+		return nil, err
+	}
+	return git.ResolveRefUpdates(in.WorkingDir(), in.Hook, updates), nil
+}
+
+// pushedCommits returns the commits introduced by all ref updates passed via stdin
+func pushedCommits(in RunInput) ([]string, error) {
+	updates, err := refUpdates(in)
+	if err != nil {
+		return nil, err
+	}
+	return git.RefUpdateCommits(updates), nil
+}
+
+// stagedContents returns the staged blobs of all added or modified regular files which are not excluded alongside
+// their contents read from the index. Symlinks are left out since their blob holds the link target.
+func stagedContents(in RunInput, excludes []string) ([]git.ChangedBlob, []string, error) {
+	staged, err := in.Repo.StagedBlobs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not determine staged files: %+v", err)
+	}
+	var blobs []git.ChangedBlob
+	var shas []string
+	for _, b := range staged {
+		if b.Regular() && !matchesAnyGlob(excludes, b.Path) {
+			blobs = append(blobs, b)
+			shas = append(shas, b.SHA)
+		}
+	}
+	contents, err := in.Repo.BlobContents(shas...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read staged files: %+v", err)
+	}
+	return blobs, contents, nil
+}
+
+// fixStaged writes the fixed staged content into the index. The working tree is fixed as well but its unstaged
+// changes are not staged. In case the working tree content cannot be fixed it is left untouched.
+func fixStaged(in RunInput, blob git.ChangedBlob, staged string, fix func(content string) (string, error)) error {
+	fixed, err := fix(staged)
+	if err != nil {
+		return err
+	}
+	sha, err := in.Repo.WriteBlob(fixed)
+	if err != nil {
+		return err
+	}
+	if err = in.Repo.UpdateIndex(blob.Mode, sha, blob.Path); err != nil {
+		return err
+	}
+	file := filepath.Join(in.WorkingDir(), blob.Path)
+	worktree, err := os.ReadFile(file)
+	if err != nil {
+		// the file was removed from the working tree after staging it
+		return nil
+	}
+	if string(worktree) != staged {
+		if fixed, err = fix(string(worktree)); err != nil {
+			return nil
+		}
+	}
+	return os.WriteFile(file, []byte(fixed), 0644)
+}
+
+// isBinary uses the same heuristic as git and treats content with a NUL byte within the first 8000 bytes as binary
+func isBinary(content string) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return strings.IndexByte(content, 0) >= 0
 }
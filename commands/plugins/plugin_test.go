@@ -0,0 +1,86 @@
+package plugins
+
+import (
+	"github.com/jenpet/giks/git"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+// testInput returns a run input for the cwd which discards all output
+func testInput(hook string, vars map[string]string, args []string) RunInput {
+	return RunInput{Hook: hook, Args: args, Vars: vars, Repo: git.NewRepository(""), Output: io.Discard}
+}
+
+func TestValidateVars(t *testing.T) {
+	validateTests := []struct {
+		name        string
+		plugin      string
+		vars        map[string]string
+		errExpected string
+	}{
+		{
+			"valid",
+			"string-validator",
+			map[string]string{"VALIDATION_PATTERN": ".+", "FAIL_ON_MISMATCH": "true"},
+			"",
+		},
+		{
+			"optional var omitted",
+			"string-validator",
+			map[string]string{"VALIDATION_PATTERN": ".+"},
+			"",
+		},
+		{
+			"missing and unknown vars",
+			"file-watcher",
+			map[string]string{"FILE_WATCHER_COMMAND": "true", "FILE_WATCHER_PATERN": ".*", "FOO": "bar"},
+			"plugin 'file-watcher' is misconfigured: required variable 'FILE_WATCHER_PATTERN' is missing, unknown variable 'FILE_WATCHER_PATERN', unknown variable 'FOO'",
+		},
+		{
+			"invalid types",
+			"string-validator",
+			map[string]string{"VALIDATION_PATTERN": "(", "FAIL_ON_MISMATCH": "yes"},
+			"plugin 'string-validator' is misconfigured: failed parsing 'VALIDATION_PATTERN' variable, expected type 'regexp' but got '(', " +
+				"failed parsing 'FAIL_ON_MISMATCH' variable, expected type 'bool' but got 'yes'",
+		},
+		{
+			"invalid number",
+			"secret-scanner",
+			map[string]string{"SECRET_SCANNER_MIN_ENTROPY": "3,5"},
+			"plugin 'secret-scanner' is misconfigured: failed parsing 'SECRET_SCANNER_MIN_ENTROPY' variable, expected type 'float' but got '3,5'",
+		},
+		{
+			"valid number",
+			"secret-scanner",
+			map[string]string{"SECRET_SCANNER_MIN_ENTROPY": "4.2"},
+			"",
+		},
+		{
+			"giks variable reference",
+			"path-policy",
+			map[string]string{"PATH_POLICY_MAX_LENGTH": "GIKS_HOOK_TYPE"},
+			"",
+		},
+	}
+	for _, tt := range validateTests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := Get(tt.plugin)
+			err := ValidateVars(p, tt.vars)
+			if tt.errExpected == "" {
+				assert.NoError(t, err, "no validation error expected")
+				return
+			}
+			assert.EqualError(t, err, tt.errExpected, "validation error does not match")
+		})
+	}
+}
+
+func TestBuiltInPlugins_shouldDescribeThemselves(t *testing.T) {
+	for _, p := range Registered() {
+		d, ok := p.(Describer)
+		assert.True(t, ok, "built-in plugin '%s' should implement Describer", p.ID())
+		assert.NotEmpty(t, d.Description(), "built-in plugin '%s' should have a description", p.ID())
+		assert.NotEmpty(t, d.Vars(), "built-in plugin '%s' should declare its vars", p.ID())
+	}
+}
@@ -0,0 +1,211 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	varProtectedPatterns      = "PROTECTED_BRANCH_PATTERNS"
+	varProtectedBlockCommits  = "PROTECTED_BRANCH_BLOCK_COMMITS"
+	varProtectedBlockPushes   = "PROTECTED_BRANCH_BLOCK_PUSHES"
+	varProtectedBlockForce    = "PROTECTED_BRANCH_BLOCK_FORCE_PUSH"
+	varProtectedBlockDeletion = "PROTECTED_BRANCH_BLOCK_DELETION"
+	varProtectedBypassVar     = "PROTECTED_BRANCH_BYPASS_VAR"
+	varProtectedBypassLog     = "PROTECTED_BRANCH_BYPASS_LOG"
+)
+
+const (
+	defaultProtectedPatterns  = "main master"
+	defaultProtectedBypassVar = "GIKS_PROTECTED_BRANCH_BYPASS"
+)
+
+// ProtectedBranch prevents commits on and pushes to protected branches
+type ProtectedBranch struct{}
+
+// protectionRules holds the configured protection of the branches
+type protectionRules struct {
+	patterns      []string
+	blockCommits  bool
+	blockPushes   bool
+	blockForce    bool
+	blockDeletion bool
+}
+
+func (pb ProtectedBranch) ID() string {
+	return "protected-branch"
+}
+
+func (pb ProtectedBranch) Description() string {
+	return "Rejects commits on protected branches as well as pushes, force pushes and deletions of protected branches."
+}
+
+func (pb ProtectedBranch) Hooks() []string {
+	return []string{git.HookPreCommit, git.HookPreMergeCommit, git.HookPrePush}
+}
+
+func (pb ProtectedBranch) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varProtectedPatterns, Type: VarTypeList, Default: defaultProtectedPatterns,
+			Help: "space separated list of globs matched against branch names, globs starting with 'refs/' are matched against the full ref"},
+		{Name: varProtectedBlockCommits, Type: VarTypeBool, Default: "true", Help: "reject commits on protected branches"},
+		{Name: varProtectedBlockPushes, Type: VarTypeBool, Default: "true", Help: "reject all pushes to protected branches including force pushes and deletions"},
+		{Name: varProtectedBlockForce, Type: VarTypeBool, Default: "true",
+			Help: "reject force pushes to protected branches, only taken into account in case '" + varProtectedBlockPushes + "' is false"},
+		{Name: varProtectedBlockDeletion, Type: VarTypeBool, Default: "true",
+			Help: "reject deletions of protected branches, only taken into account in case '" + varProtectedBlockPushes + "' is false"},
+		{Name: varProtectedBypassVar, Type: VarTypeString, Default: defaultProtectedBypassVar,
+			Help: "environment variable which bypasses the protection in case it is set to a non-empty value, e.g. a reason"},
+		{Name: varProtectedBypassLog, Type: VarTypeString, Help: "file relative to the repository every bypass is appended to"},
+	}
+}
+
+func (pb ProtectedBranch) Run(ctx context.Context, in RunInput) Result {
+	if !contains(pb.Hooks(), in.Hook) {
+		return hookUnsupported(in.Hook, pb.ID())
+	}
+	rules, err := protectionRulesFromVars(in.Vars)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	var violations []Finding
+	if in.Hook == git.HookPrePush {
+		updates, err := refUpdates(in)
+		if err != nil {
+			return Errorf("%s", err)
+		}
+		violations = rules.checkPush(updates)
+	} else if branch, err := in.Repo.CurrentBranch(); err == nil {
+		// detached HEAD states are never protected
+		violations = rules.checkCommit(branch)
+	}
+	if len(violations) == 0 {
+		return Pass()
+	}
+
+	bypassVar := in.Vars[varProtectedBypassVar]
+	if strings.TrimSpace(bypassVar) == "" {
+		bypassVar = defaultProtectedBypassVar
+	}
+	if reason := os.Getenv(bypassVar); reason != "" {
+		if err = logBypass(in, bypassVar, reason, violations); err != nil {
+			return Errorf("%s", err)
+		}
+		return Pass()
+	}
+	return Fail(true, fmt.Sprintf("protected branches are affected. Set '%s=<reason>' to bypass the protection", bypassVar), violations...)
+}
+
+func protectionRulesFromVars(vars Vars) (protectionRules, error) {
+	r := protectionRules{patterns: vars.List(varProtectedPatterns)}
+	if len(r.patterns) == 0 {
+		r.patterns = strings.Fields(defaultProtectedPatterns)
+	}
+	for _, p := range r.patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return r, fmt.Errorf("provided glob '%s' is malformed", p)
+		}
+	}
+	flags := []struct {
+		name  string
+		value *bool
+	}{
+		{varProtectedBlockCommits, &r.blockCommits},
+		{varProtectedBlockPushes, &r.blockPushes},
+		{varProtectedBlockForce, &r.blockForce},
+		{varProtectedBlockDeletion, &r.blockDeletion},
+	}
+	for _, f := range flags {
+		*f.value = true
+		if _, ok := vars[f.name]; !ok {
+			continue
+		}
+		b, err := vars.Bool(f.name, false)
+		if err != nil {
+			return r, err
+		}
+		*f.value = b
+	}
+	return r, nil
+}
+
+// protected returns whether the branch or ref matches one of the patterns
+func (r protectionRules) protected(ref string) bool {
+	branch := strings.TrimPrefix(ref, "refs/heads/")
+	for _, p := range r.patterns {
+		target := branch
+		if strings.HasPrefix(p, "refs/") {
+			target = ref
+		}
+		if ok, _ := path.Match(p, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r protectionRules) checkCommit(branch string) []Finding {
+	if r.blockCommits && r.protected("refs/heads/"+branch) {
+		return []Finding{{Message: fmt.Sprintf("committing on protected branch '%s' is not allowed", branch)}}
+	}
+	return nil
+}
+
+// checkPush returns a violation for every update of a protected ref. Blocked pushes reject every update, otherwise
+// deletions and force pushes are only rejected in case they are blocked on their own.
+func (r protectionRules) checkPush(updates []git.RefUpdate) []Finding {
+	var violations []Finding
+	for _, u := range updates {
+		if !r.protected(u.RemoteRef) {
+			continue
+		}
+		var msg string
+		switch {
+		case u.Deletion:
+			if r.blockDeletion || r.blockPushes {
+				msg = "deleting protected branch '%s' is not allowed"
+			}
+		case u.Force:
+			if r.blockForce || r.blockPushes {
+				msg = "force pushing to protected branch '%s' is not allowed"
+			}
+		case r.blockPushes:
+			msg = "pushing to protected branch '%s' is not allowed"
+		}
+		if msg != "" {
+			violations = append(violations, Finding{Message: fmt.Sprintf(msg, strings.TrimPrefix(u.RemoteRef, "refs/heads/"))})
+		}
+	}
+	return violations
+}
+
+// logBypass logs the bypassed violations and appends them to the bypass log file in case one is configured
+func logBypass(in RunInput, bypassVar string, reason string, violations []Finding) error {
+	user, _ := in.Repo.Command("config", "user.email")
+	for _, v := range violations {
+		log.Warnf("Protection bypassed via '%s' by '%s' (reason: %s): %s", bypassVar, user, reason, v.Message)
+	}
+	file := strings.TrimSpace(in.Vars[varProtectedBypassLog])
+	if file == "" {
+		return nil
+	}
+	f, err := os.OpenFile(filepath.Join(in.WorkingDir(), file), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open bypass log: %+v", err)
+	}
+	defer f.Close()
+	for _, v := range violations {
+		line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n", time.Now().Format(time.RFC3339), user, in.Hook, reason, v.Message)
+		if _, err = f.WriteString(line); err != nil {
+			return fmt.Errorf("could not write bypass log: %+v", err)
+		}
+	}
+	return nil
+}
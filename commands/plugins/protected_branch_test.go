@@ -0,0 +1,107 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProtectedBranchCommit(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	_, _ = tr.Command("checkout", "-b", "release/1.0")
+
+	commitTests := []struct {
+		name           string
+		hook           string
+		vars           map[string]string
+		statusExpected Status
+	}{
+		{"unprotected branch", git.HookPreCommit, map[string]string{}, StatusPass},
+		{"protected branch", git.HookPreCommit, map[string]string{"PROTECTED_BRANCH_PATTERNS": "main release/*"}, StatusFail},
+		{"protected merge", git.HookPreMergeCommit, map[string]string{"PROTECTED_BRANCH_PATTERNS": "refs/heads/release/*"}, StatusFail},
+		{"commits allowed", git.HookPreCommit, map[string]string{"PROTECTED_BRANCH_PATTERNS": "release/*", "PROTECTED_BRANCH_BLOCK_COMMITS": "false"}, StatusPass},
+		{"unsupported hook", git.HookCommitMsg, map[string]string{}, StatusSkip},
+	}
+	pb, _ := Get("protected-branch")
+	for _, tt := range commitTests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := testInput(tt.hook, tt.vars, nil)
+			in.Repo = git.NewRepository(tr.AbsDir())
+			res := pb.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match result: %s", res)
+		})
+	}
+}
+
+func TestProtectedBranchPush(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("a.txt", "a")
+	tr.AddAll()
+	tr.Commit("first")
+	first, _ := tr.Command("rev-parse", "HEAD")
+	tr.WriteFile("a.txt", "b")
+	tr.AddAll()
+	tr.Commit("second")
+	second, _ := tr.Command("rev-parse", "HEAD")
+	first, second = strings.TrimSpace(first), strings.TrimSpace(second)
+
+	pushTests := []struct {
+		name           string
+		stdin          string
+		vars           map[string]string
+		statusExpected Status
+	}{
+		{"push to unprotected branch", "refs/heads/feat " + second + " refs/heads/feat " + first, map[string]string{}, StatusPass},
+		{"push to protected branch", "refs/heads/feat " + second + " refs/heads/main " + first, map[string]string{}, StatusFail},
+		{"fast-forward allowed", "refs/heads/feat " + second + " refs/heads/main " + first,
+			map[string]string{"PROTECTED_BRANCH_BLOCK_PUSHES": "false"}, StatusPass},
+		{"force push blocked", "refs/heads/feat " + first + " refs/heads/main " + second,
+			map[string]string{"PROTECTED_BRANCH_BLOCK_PUSHES": "false"}, StatusFail},
+		{"force push allowed", "refs/heads/feat " + first + " refs/heads/main " + second,
+			map[string]string{"PROTECTED_BRANCH_BLOCK_PUSHES": "false", "PROTECTED_BRANCH_BLOCK_FORCE_PUSH": "false"}, StatusPass},
+		{"force push blocked by blocked pushes", "refs/heads/feat " + first + " refs/heads/main " + second,
+			map[string]string{"PROTECTED_BRANCH_BLOCK_FORCE_PUSH": "false"}, StatusFail},
+		{"deletion blocked", "(delete) " + zeroSHA + " refs/heads/main " + second,
+			map[string]string{"PROTECTED_BRANCH_BLOCK_PUSHES": "false", "PROTECTED_BRANCH_BLOCK_FORCE_PUSH": "false"}, StatusFail},
+		{"deletion allowed", "(delete) " + zeroSHA + " refs/heads/main " + second,
+			map[string]string{"PROTECTED_BRANCH_BLOCK_PUSHES": "false", "PROTECTED_BRANCH_BLOCK_DELETION": "false"}, StatusPass},
+		{"deletion blocked by blocked pushes", "(delete) " + zeroSHA + " refs/heads/main " + second,
+			map[string]string{"PROTECTED_BRANCH_BLOCK_DELETION": "false"}, StatusFail},
+		{"protected tags", "refs/tags/v1 " + second + " refs/tags/v1 " + zeroSHA,
+			map[string]string{"PROTECTED_BRANCH_PATTERNS": "refs/tags/*"}, StatusFail},
+	}
+	pb, _ := Get("protected-branch")
+	for _, tt := range pushTests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := testInput(git.HookPrePush, tt.vars, nil)
+			in.Repo = git.NewRepository(tr.AbsDir())
+			in.Stdin = strings.NewReader(tt.stdin + "\n")
+			res := pb.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match result: %s", res)
+		})
+	}
+}
+
+func TestProtectedBranchBypass(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	_, _ = tr.Command("checkout", "-b", "main")
+	_ = os.Setenv("GIKS_TEST_BYPASS", "hotfix for incident")
+	defer os.Unsetenv("GIKS_TEST_BYPASS")
+
+	vars := map[string]string{"PROTECTED_BRANCH_BYPASS_VAR": "GIKS_TEST_BYPASS", "PROTECTED_BRANCH_BYPASS_LOG": "bypass.log"}
+	in := testInput(git.HookPreCommit, vars, nil)
+	in.Repo = git.NewRepository(tr.AbsDir())
+	pb, _ := Get("protected-branch")
+	res := pb.Run(context.Background(), in)
+	assert.Equal(t, StatusPass, res.Status, "protection should be bypassed: %s", res)
+
+	b, err := os.ReadFile(filepath.Join(tr.AbsDir(), "bypass.log"))
+	assert.NoError(t, err, "bypass should be logged")
+	assert.Contains(t, string(b), "giks@example.com\tpre-commit\thotfix for incident\tcommitting on protected branch 'main'",
+		"bypass log should contain the user, hook, reason and violation")
+}
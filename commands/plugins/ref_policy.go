@@ -0,0 +1,200 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	varRefPolicyRules      = "REF_POLICY_RULES"
+	varRefPolicyUserVar    = "REF_POLICY_USER_VAR"
+	varRefPolicyNoForce    = "REF_POLICY_NO_FORCE"
+	varRefPolicyLinear     = "REF_POLICY_LINEAR"
+	varRefPolicyMaxCommits = "REF_POLICY_MAX_COMMITS"
+)
+
+const (
+	refOperationCreate   = "create"
+	refOperationUpdate   = "update"
+	refOperationDelete   = "delete"
+	refPolicyAny         = "*"
+	defaultRefPolicyUser = "REMOTE_USER"
+)
+
+var refOperations = []string{refOperationCreate, refOperationUpdate, refOperationDelete}
+
+// RefPolicy enforces policies on the ref updates received by a server repository
+type RefPolicy struct{}
+
+// refRule restricts the operations on refs matching the glob to the listed users
+type refRule struct {
+	glob       string
+	operations []string
+	users      []string
+}
+
+func (rp RefPolicy) ID() string {
+	return "ref-policy"
+}
+
+func (rp RefPolicy) Description() string {
+	return "Enforces who may create, update or delete refs, rejects non fast-forward updates and merge commits on listed refs and limits the commits per push on the server."
+}
+
+func (rp RefPolicy) Hooks() []string {
+	return []string{git.HookPreReceive, git.HookUpdate}
+}
+
+func (rp RefPolicy) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varRefPolicyRules, Type: VarTypeString,
+			Help: "newline separated list of 'glob operations [user...]' entries, e.g. 'refs/tags/** create,delete release-bot'. Operations are a comma separated list of 'create', 'update', 'delete' or '*'. The first rule matching the ref and operation applies and only allows the listed users, '*' allows anyone"},
+		{Name: varRefPolicyUserVar, Type: VarTypeString, Default: defaultRefPolicyUser,
+			Help: "environment variable holding the name of the pushing user as provided by the git server"},
+		{Name: varRefPolicyNoForce, Type: VarTypeList, Help: "space separated list of ref globs which only accept fast-forward updates, e.g. 'refs/heads/main refs/tags/**'"},
+		{Name: varRefPolicyLinear, Type: VarTypeList, Help: "space separated list of ref globs which do not accept merge commits"},
+		{Name: varRefPolicyMaxCommits, Type: VarTypeInt, Default: "0",
+			Help: "maximum amount of new commits per push, 0 disables the limit. The update hook applies the limit per ref"},
+	}
+}
+
+func (rp RefPolicy) Run(ctx context.Context, in RunInput) Result {
+	if !contains(rp.Hooks(), in.Hook) {
+		return hookUnsupported(in.Hook, rp.ID())
+	}
+	rules, err := refRules(in.Vars[varRefPolicyRules])
+	if err != nil {
+		return Errorf("variable '%s' is invalid: %s", varRefPolicyRules, err)
+	}
+	maxCommits := 0
+	if _, ok := in.Vars[varRefPolicyMaxCommits]; ok {
+		if maxCommits, err = in.Vars.Int(varRefPolicyMaxCommits, false); err != nil {
+			return Errorf("%s", err)
+		}
+	}
+	userVar := defaultRefPolicyUser
+	if v := in.Vars[varRefPolicyUserVar]; v != "" {
+		userVar = v
+	}
+	user := os.Getenv(userVar)
+	noForce := in.Vars.List(varRefPolicyNoForce)
+	linear := in.Vars.List(varRefPolicyLinear)
+
+	updates, err := refUpdates(in)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	var findings []Finding
+	var commits []string
+	for _, u := range updates {
+		ref := u.RemoteRef
+		op := refOperation(u)
+		if problem := violatedRefRule(ref, op, user, rules); problem != "" {
+			findings = append(findings, Finding{File: ref, Message: problem})
+		}
+		if u.Force && matchesAnyGlob(noForce, ref) {
+			findings = append(findings, Finding{File: ref, Message: "non fast-forward updates are not allowed"})
+		}
+		if len(u.Commits) > 0 && matchesAnyGlob(linear, ref) {
+			merges, err := mergeCommits(in.Repo, u.Commits)
+			if err != nil {
+				return Errorf("%s", err)
+			}
+			for _, c := range merges {
+				findings = append(findings, Finding{File: ref, Message: fmt.Sprintf("merge commit %s '%s' is not allowed, history has to be linear",
+					c.ShortSHA(), c.Subject())})
+			}
+		}
+		for _, c := range u.Commits {
+			commits = git.AppendUnique(commits, c)
+		}
+	}
+	if maxCommits > 0 && len(commits) > maxCommits {
+		findings = append(findings, Finding{Message: fmt.Sprintf("push contains %d new commits, the maximum is %d", len(commits), maxCommits)})
+	}
+	if len(findings) > 0 {
+		return Fail(true, "pushed ref updates violate the ref policy", findings...)
+	}
+	return Pass()
+}
+
+// refRules parses the 'glob operations [user...]' entries
+func refRules(list string) ([]refRule, error) {
+	var rules []refRule
+	for _, line := range strings.Split(list, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("rule '%s' is malformed, expected 'glob operations [user...]'", strings.TrimSpace(line))
+		}
+		if _, err := path.Match(fields[0], ""); err != nil {
+			return nil, fmt.Errorf("provided glob '%s' is malformed", fields[0])
+		}
+		r := refRule{glob: fields[0], users: fields[2:]}
+		for _, op := range strings.Split(fields[1], ",") {
+			if op == refPolicyAny {
+				r.operations = refOperations
+				continue
+			}
+			if !contains(refOperations, op) {
+				return nil, fmt.Errorf("unknown operation '%s'. Supported operations are '%s' and '%s'", op, strings.Join(refOperations, "', '"), refPolicyAny)
+			}
+			r.operations = git.AppendUnique(r.operations, op)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// refOperation returns the operation the update performs on the ref
+func refOperation(u git.RefUpdate) string {
+	switch {
+	case u.Deletion:
+		return refOperationDelete
+	case u.NewBranch:
+		return refOperationCreate
+	}
+	return refOperationUpdate
+}
+
+// violatedRefRule applies the first rule matching the ref and operation. Operations which are not covered by any
+// rule are allowed.
+func violatedRefRule(ref string, op string, user string, rules []refRule) string {
+	for _, r := range rules {
+		if !contains(r.operations, op) || !matchesAnyGlob([]string{r.glob}, ref) {
+			continue
+		}
+		if contains(r.users, refPolicyAny) || user != "" && contains(r.users, user) {
+			return ""
+		}
+		if len(r.users) == 0 {
+			return fmt.Sprintf("nobody may %s refs matching '%s'", op, r.glob)
+		}
+		if user == "" {
+			return fmt.Sprintf("only '%s' may %s refs matching '%s' but the pushing user is unknown", strings.Join(r.users, "', '"), op, r.glob)
+		}
+		return fmt.Sprintf("user '%s' may not %s refs matching '%s', only '%s' may", user, op, r.glob, strings.Join(r.users, "', '"))
+	}
+	return ""
+}
+
+// mergeCommits returns the commits with more than one parent
+func mergeCommits(repo git.Repository, shas []string) ([]git.Commit, error) {
+	commits, err := repo.Commits(shas...)
+	if err != nil {
+		return nil, fmt.Errorf("could not read pushed commits: %+v", err)
+	}
+	var merges []git.Commit
+	for _, c := range commits {
+		if len(c.Parents) > 1 {
+			merges = append(merges, c)
+		}
+	}
+	return merges, nil
+}
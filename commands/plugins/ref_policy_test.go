@@ -0,0 +1,102 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRefPolicy(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	head := func() string {
+		out, _ := tr.Command("rev-parse", "HEAD")
+		return strings.TrimSpace(out)
+	}
+	_, _ = tr.Command("checkout", "-q", "-b", "main")
+	tr.WriteFile("README", "first")
+	tr.AddAll()
+	tr.Commit("first")
+	first := head()
+	tr.WriteFile("README", "second")
+	tr.AddAll()
+	tr.Commit("second")
+	second := head()
+	// the server repository only knows the commits pushed so far
+	bare := filepath.Join(t.TempDir(), "server.git")
+	assert.NoError(t, exec.Command("git", "clone", "-q", "--bare", tr.AbsDir(), bare).Run(), "bare repository should be cloned")
+
+	_, _ = tr.Command("checkout", "-q", "-b", "side", first)
+	tr.WriteFile("SIDE", "side")
+	tr.AddAll()
+	tr.Commit("side")
+	_, _ = tr.Command("checkout", "-q", "main")
+	_, _ = tr.Command("merge", "-q", "--no-ff", "-m", "merge side", "side")
+	merge := head()
+	_, _ = tr.Command("checkout", "-q", "-b", "rewritten", first)
+	tr.WriteFile("README", "rewritten")
+	tr.AddAll()
+	tr.Commit("rewritten")
+	rewritten := head()
+	// received objects are available to the hooks without being referenced by the server refs
+	assert.NoError(t, exec.Command("git", "-C", bare, "fetch", "-q", tr.AbsDir(), "refs/heads/main", "refs/heads/rewritten").Run(),
+		"objects should be received")
+
+	policyTests := []struct {
+		name           string
+		hook           string
+		update         []string
+		vars           map[string]string
+		user           string
+		statusExpected Status
+	}{
+		{"no policy", git.HookPreReceive, []string{"refs/heads/main", second, merge}, map[string]string{}, "", StatusPass},
+		{"merge commits on linear ref", git.HookPreReceive, []string{"refs/heads/main", second, merge},
+			map[string]string{"REF_POLICY_LINEAR": "refs/heads/main"}, "", StatusFail},
+		{"merge commits on other ref", git.HookUpdate, []string{"refs/heads/main", second, merge},
+			map[string]string{"REF_POLICY_LINEAR": "refs/heads/release/**"}, "", StatusPass},
+		{"non fast-forward on listed ref", git.HookUpdate, []string{"refs/heads/main", second, rewritten},
+			map[string]string{"REF_POLICY_NO_FORCE": "refs/heads/**"}, "", StatusFail},
+		{"fast-forward on listed ref", git.HookUpdate, []string{"refs/heads/main", second, merge},
+			map[string]string{"REF_POLICY_NO_FORCE": "refs/heads/**"}, "", StatusPass},
+		{"tag creation by other user", git.HookPreReceive, []string{"refs/tags/v1", zeroSHA, second},
+			map[string]string{"REF_POLICY_RULES": "refs/tags/** create,delete release-bot"}, "alice", StatusFail},
+		{"tag creation by unknown user", git.HookUpdate, []string{"refs/tags/v1", zeroSHA, second},
+			map[string]string{"REF_POLICY_RULES": "refs/tags/** create,delete release-bot"}, "", StatusFail},
+		{"tag creation by listed user", git.HookPreReceive, []string{"refs/tags/v1", zeroSHA, second},
+			map[string]string{"REF_POLICY_RULES": "refs/tags/** create,delete release-bot"}, "release-bot", StatusPass},
+		{"tag deletion by nobody", git.HookUpdate, []string{"refs/tags/v1", second, zeroSHA},
+			map[string]string{"REF_POLICY_RULES": "refs/tags/** delete\nrefs/tags/** * release-bot"}, "release-bot", StatusFail},
+		{"first matching rule", git.HookPreReceive, []string{"refs/heads/main", second, merge},
+			map[string]string{"REF_POLICY_RULES": "refs/heads/main update *\nrefs/heads/** * release-bot"}, "alice", StatusPass},
+		{"too many commits", git.HookPreReceive, []string{"refs/heads/main", second, merge},
+			map[string]string{"REF_POLICY_MAX_COMMITS": "1"}, "", StatusFail},
+		{"commits within limit", git.HookPreReceive, []string{"refs/heads/main", second, merge},
+			map[string]string{"REF_POLICY_MAX_COMMITS": "2"}, "", StatusPass},
+		{"malformed rule", git.HookPreReceive, []string{"refs/heads/main", second, merge},
+			map[string]string{"REF_POLICY_RULES": "refs/heads/** push"}, "", StatusError},
+		{"unsupported hook", git.HookPrePush, []string{"refs/heads/main", second, merge}, map[string]string{}, "", StatusSkip},
+	}
+	rp, _ := Get("ref-policy")
+	defer os.Unsetenv("GIKS_TEST_PUSHER")
+	for _, tt := range policyTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Setenv("GIKS_TEST_PUSHER", tt.user)
+			tt.vars["REF_POLICY_USER_VAR"] = "GIKS_TEST_PUSHER"
+			in := testInput(tt.hook, tt.vars, nil)
+			if tt.hook == git.HookUpdate {
+				in.Args = tt.update
+			} else {
+				in.Stdin = strings.NewReader(tt.update[1] + " " + tt.update[2] + " " + tt.update[0] + "\n")
+			}
+			in.Repo = git.NewRepository(bare)
+			res := rp.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match result: %s", res)
+		})
+	}
+}
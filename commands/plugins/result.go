@@ -0,0 +1,93 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Status describes the outcome of a plugin run
+type Status string
+
+const (
+	// StatusPass indicates that the plugin did not find any problems
+	StatusPass Status = "pass"
+	// StatusWarn indicates problems which should not prevent further steps from being executed
+	StatusWarn Status = "warn"
+	// StatusFail indicates problems which prevent further steps from being executed
+	StatusFail Status = "fail"
+	// StatusSkip indicates that the plugin did not check anything, e.g. due to an unsupported hook
+	StatusSkip Status = "skip"
+	// StatusError indicates that the plugin could not be executed properly, e.g. due to a misconfiguration
+	StatusError Status = "error"
+)
+
+// Result is returned by every plugin run
+type Result struct {
+	Status   Status
+	Message  string
+	Findings []Finding
+}
+
+// Finding points at a specific location which caused a plugin to warn or fail. Line and column are 1-based and
+// omitted if zero.
+type Finding struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Pass returns a passing result
+func Pass() Result {
+	return Result{Status: StatusPass}
+}
+
+// Skip returns a result indicating that the plugin did not check anything
+func Skip(format string, args ...interface{}) Result {
+	return Result{Status: StatusSkip, Message: fmt.Sprintf(format, args...)}
+}
+
+// Errorf returns a result indicating that the plugin could not be executed properly
+func Errorf(format string, args ...interface{}) Result {
+	return Result{Status: StatusError, Message: fmt.Sprintf(format, args...)}
+}
+
+// Fail returns a failing result in case blocking is set, otherwise a warning
+func Fail(blocking bool, msg string, findings ...Finding) Result {
+	status := StatusWarn
+	if blocking {
+		status = StatusFail
+	}
+	return Result{Status: status, Message: msg, Findings: findings}
+}
+
+// Failed returns whether the result should prevent further steps from being executed
+func (r Result) Failed() bool {
+	return r.Status == StatusFail || r.Status == StatusError
+}
+
+// String returns the message of the result followed by one finding per line
+func (r Result) String() string {
+	lines := []string{}
+	if r.Message != "" {
+		lines = append(lines, r.Message)
+	}
+	for _, f := range r.Findings {
+		lines = append(lines, f.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (f Finding) String() string {
+	location := f.File
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, f.Line)
+		if f.Column > 0 {
+			location = fmt.Sprintf("%s:%d", location, f.Column)
+		}
+	}
+	if location == "" {
+		return f.Message
+	}
+	return fmt.Sprintf("%s: %s", location, f.Message)
+}
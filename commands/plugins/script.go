@@ -0,0 +1,155 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// ScriptPluginDirectory is the directory within a repository holding script plugins, one sub-directory per plugin
+	ScriptPluginDirectory = ".giks/plugins"
+	// scriptManifestFile is the name of the manifest every script plugin directory has to contain
+	scriptManifestFile = "plugin.yml"
+)
+
+// ScriptPlugin is a plugin consisting of an entry script and a manifest located in a sub-directory of the
+// ScriptPluginDirectory. The name of the sub-directory is the ID of the plugin.
+type ScriptPlugin struct {
+	name     string
+	dir      string
+	manifest ScriptManifest
+}
+
+// ScriptManifest declares the entry script of a script plugin, the hooks it supports and the variables it requires
+type ScriptManifest struct {
+	// Entry is the path of the script relative to the plugin directory
+	Entry       string `yaml:"entry"`
+	Description string `yaml:"description"`
+	// Hooks supported by the plugin. An empty list supports all hooks.
+	Hooks []string `yaml:"hooks"`
+	// Blocking defines whether a failing script prevents further steps from being executed (default: true)
+	Blocking *bool                `yaml:"blocking"`
+	Vars     map[string]ScriptVar `yaml:"vars"`
+}
+
+// ScriptVar declares a single variable of a script plugin
+type ScriptVar struct {
+	Required    bool   `yaml:"required"`
+	Default     string `yaml:"default"`
+	Description string `yaml:"description"`
+}
+
+// FindScript looks up the script plugin with the given name within the given directory which usually is the
+// ScriptPluginDirectory of a repository.
+func FindScript(dir string, name string) (Plugin, error) {
+	if err := validatePluginName(name); err != nil {
+		return nil, err
+	}
+	pluginDir := filepath.Join(dir, name)
+	b, err := os.ReadFile(filepath.Join(pluginDir, scriptManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("plugin '%s' not found", name)
+	}
+	var m ScriptManifest
+	if err = yaml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("manifest of script plugin '%s' is malformed: %+v", name, err)
+	}
+	if strings.TrimSpace(m.Entry) == "" {
+		return nil, fmt.Errorf("manifest of script plugin '%s' does not declare an entry", name)
+	}
+	return ScriptPlugin{name: name, dir: pluginDir, manifest: m}, nil
+}
+
+func (sp ScriptPlugin) ID() string {
+	return sp.name
+}
+
+func (sp ScriptPlugin) Description() string {
+	return sp.manifest.Description
+}
+
+func (sp ScriptPlugin) Hooks() []string {
+	return sp.manifest.Hooks
+}
+
+func (sp ScriptPlugin) Vars() []VarSpec {
+	specs := make([]VarSpec, 0, len(sp.manifest.Vars))
+	for name, v := range sp.manifest.Vars {
+		specs = append(specs, VarSpec{Name: name, Type: VarTypeString, Required: v.Required, Default: v.Default, Help: v.Description})
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+func (sp ScriptPlugin) Run(ctx context.Context, in RunInput) Result {
+	if !sp.supports(in.Hook) {
+		return hookUnsupported(in.Hook, sp.ID())
+	}
+	if err := sp.applyVars(in.Vars); err != nil {
+		return Errorf("%s", err)
+	}
+	entry := filepath.Join(sp.dir, sp.manifest.Entry)
+	stat, err := os.Stat(entry)
+	if err != nil {
+		return Errorf("entry of script plugin '%s' is not accessible: %+v", sp.name, err)
+	}
+	args := in.Args
+	bin := entry
+	// use the shell in case the entry is not executable
+	if stat.Mode()&0100 == 0 {
+		bin = "sh"
+		args = append([]string{entry}, args...)
+	}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = in.WorkingDir()
+	cmd.Env = os.Environ()
+	for k, v := range in.Vars {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	cmd.Stdin = in.Stdin
+	cmd.Stdout = in.Output
+	cmd.Stderr = in.Output
+	if err = cmd.Run(); err != nil {
+		return Fail(sp.blocking(), fmt.Sprintf("script plugin '%s' failed: %+v", sp.name, err))
+	}
+	return Pass()
+}
+
+func (sp ScriptPlugin) supports(hook string) bool {
+	if len(sp.manifest.Hooks) == 0 {
+		return true
+	}
+	return contains(sp.manifest.Hooks, hook)
+}
+
+func (sp ScriptPlugin) blocking() bool {
+	return sp.manifest.Blocking == nil || *sp.manifest.Blocking
+}
+
+// applyVars validates the variables against the manifest and sets default values for absent or empty variables
+func (sp ScriptPlugin) applyVars(vars Vars) error {
+	var missing []string
+	for name, v := range sp.manifest.Vars {
+		if strings.TrimSpace(vars[name]) != "" {
+			continue
+		}
+		if v.Default != "" {
+			vars[name] = v.Default
+			continue
+		}
+		if v.Required {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("script plugin '%s' requires variable(s) '%s'", sp.name, strings.Join(missing, "', '"))
+	}
+	return nil
+}
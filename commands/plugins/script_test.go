@@ -0,0 +1,65 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const scriptTestManifest = `
+entry: run.sh
+description: fails in case GREETING is not 'hello'
+hooks: [pre-commit]
+blocking: false
+vars:
+  GREETING:
+    required: true
+    description: greeting which is checked
+  TARGET:
+    default: world
+`
+
+func TestScriptPlugin(t *testing.T) {
+	workingDir := t.TempDir()
+	dir := filepath.Join(workingDir, ScriptPluginDirectory, "greeter")
+	_ = os.MkdirAll(dir, 0777)
+	_ = os.WriteFile(filepath.Join(dir, "plugin.yml"), []byte(scriptTestManifest), 0644)
+	// non-executable entry which has to be run via the shell
+	_ = os.WriteFile(filepath.Join(dir, "run.sh"), []byte(`[ "${GREETING} ${TARGET}" = "hello world" ]`), 0644)
+
+	p, err := Lookup("greeter", workingDir, "")
+	assert.NoError(t, err, "script plugin should be found within the repository")
+	assert.Equal(t, "greeter", p.ID(), "script plugin should use its directory name as ID")
+
+	scriptTests := []struct {
+		name           string
+		hook           string
+		vars           map[string]string
+		statusExpected Status
+	}{
+		{"passes with default", "pre-commit", map[string]string{"GREETING": "hello"}, StatusPass},
+		{"fails without blocking", "pre-commit", map[string]string{"GREETING": "bye"}, StatusWarn},
+		{"missing required var", "pre-commit", map[string]string{}, StatusError},
+		{"unsupported hook", "commit-msg", map[string]string{}, StatusSkip},
+	}
+	for _, tt := range scriptTests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := testInput(tt.hook, tt.vars, nil)
+			in.Repo = git.NewRepository(workingDir)
+			res := p.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match executed plugin result")
+		})
+	}
+
+	for _, name := range []string{"../plugins/greeter", "..", `greeter\..`} {
+		_, err = Lookup(name, workingDir, "")
+		assert.Error(t, err, "plugin name '%s' should be rejected", name)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "plugin.yml"), []byte("description: no entry"), 0644)
+	_, err = Lookup("greeter", workingDir, "")
+	assert.Error(t, err, "script plugin without entry should be invalid")
+}
@@ -0,0 +1,250 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	varSecretRules       = "SECRET_SCANNER_RULES"
+	varSecretAllowlist   = "SECRET_SCANNER_ALLOWLIST"
+	varSecretMinEntropy  = "SECRET_SCANNER_MIN_ENTROPY"
+	varSecretFailOnMatch = "SECRET_SCANNER_FAIL_ON_MATCH"
+)
+
+const (
+	defaultSecretAllowlist  = ".giks/secrets-allowlist"
+	defaultSecretMinEntropy = 3.5
+)
+
+// secretRule detects a secret within a single line. The secret is the first capture group in case the expression
+// has one, otherwise the whole match.
+type secretRule struct {
+	id     string
+	regexp *regexp.Regexp
+	// entropy indicates that the secret has to exceed the minimum entropy in order to be reported
+	entropy bool
+}
+
+var builtinSecretRules = []secretRule{
+	{id: "aws-access-key-id", regexp: regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16})\b`)},
+	{id: "aws-secret-access-key", regexp: regexp.MustCompile(`(?i)aws_?secret_?access_?key\S*\s*[:=]+\s*["']?([A-Za-z0-9/+=]{40})\b`)},
+	{id: "private-key", regexp: regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{id: "jwt", regexp: regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`)},
+	{id: "generic-secret", entropy: true,
+		regexp: regexp.MustCompile(`(?i)(?:secret|token|passw(?:or)?d|pwd|api_?key|access_?key|auth_?key|credentials?)[A-Za-z0-9_.-]*["']?\s*[:=]+\s*["']?([A-Za-z0-9_\-+/=.~]{16,})`)},
+}
+
+// SecretScanner scans the lines added by the staged changes or the pushed commits for secrets
+type SecretScanner struct{}
+
+// secretMatch is a detected secret within an added line
+type secretMatch struct {
+	rule   string
+	secret string
+	line   git.AddedLine
+	column int
+}
+
+func (ss SecretScanner) ID() string {
+	return "secret-scanner"
+}
+
+func (ss SecretScanner) Description() string {
+	return "Scans the lines added by the staged changes or the pushed commits for secrets like keys and tokens."
+}
+
+func (ss SecretScanner) Hooks() []string {
+	return []string{git.HookPreCommit, git.HookPrePush}
+}
+
+func (ss SecretScanner) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varSecretRules, Type: VarTypeString, Help: "newline separated list of additional rules in the format 'id=regexp'. " +
+			"The first capture group is treated as the secret"},
+		{Name: varSecretAllowlist, Type: VarTypeString, Default: defaultSecretAllowlist,
+			Help: "file relative to the repository holding one fingerprint of an accepted secret per line"},
+		{Name: varSecretMinEntropy, Type: VarTypeFloat, Default: fmt.Sprint(defaultSecretMinEntropy),
+			Help: "minimum shannon entropy (bits per character) of generic secret assignments"},
+		{Name: varSecretFailOnMatch, Type: VarTypeBool, Default: "true", Help: "fail instead of warn in case a secret was found"},
+	}
+}
+
+func (ss SecretScanner) Run(ctx context.Context, in RunInput) Result {
+	if !contains(ss.Hooks(), in.Hook) {
+		return hookUnsupported(in.Hook, ss.ID())
+	}
+	rules, err := secretRules(in.Vars[varSecretRules])
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	minEntropy := defaultSecretMinEntropy
+	if strings.TrimSpace(in.Vars[varSecretMinEntropy]) != "" {
+		if minEntropy, err = in.Vars.Float(varSecretMinEntropy, false); err != nil {
+			return Errorf("%s", err)
+		}
+	}
+	failOnMatch := true
+	if _, ok := in.Vars[varSecretFailOnMatch]; ok {
+		if failOnMatch, err = in.Vars.Bool(varSecretFailOnMatch, false); err != nil {
+			return Errorf("%s", err)
+		}
+	}
+	allowlistFile := in.Vars[varSecretAllowlist]
+	if strings.TrimSpace(allowlistFile) == "" {
+		allowlistFile = defaultSecretAllowlist
+	}
+	allowed, err := readAllowlist(filepath.Join(in.WorkingDir(), allowlistFile))
+	if err != nil {
+		return Errorf("%s", err)
+	}
+
+	var findings []Finding
+	report := func(lines []git.AddedLine, commit string) {
+		for _, m := range scanLines(lines, rules, minEntropy) {
+			fingerprint := secretFingerprint(m.rule, m.secret)
+			if allowed[fingerprint] {
+				continue
+			}
+			msg := fmt.Sprintf("possible secret '%s' detected by rule '%s' (fingerprint: %s)", maskSecret(m.secret), m.rule, fingerprint)
+			if commit != "" {
+				msg = fmt.Sprintf("%s in commit %.7s", msg, commit)
+			}
+			findings = append(findings, Finding{File: m.line.File, Line: m.line.Line, Column: m.column, Message: msg})
+		}
+	}
+	if in.Hook == git.HookPreCommit {
+		lines, err := in.Repo.StagedAddedLines()
+		if err != nil {
+			return Errorf("could not determine staged changes: %+v", err)
+		}
+		report(lines, "")
+	} else {
+		commits, err := pushedCommits(in)
+		if err != nil {
+			return Errorf("%s", err)
+		}
+		for _, c := range commits {
+			lines, err := in.Repo.CommitAddedLines(c)
+			if err != nil {
+				return Errorf("could not determine changes of commit '%s': %+v", c, err)
+			}
+			report(lines, c)
+		}
+	}
+	if len(findings) > 0 {
+		return Fail(failOnMatch, fmt.Sprintf("found %d possible secret(s). Add the fingerprint to '%s' in case it is a false positive", len(findings), allowlistFile), findings...)
+	}
+	return Pass()
+}
+
+// secretRules returns the built-in rules followed by the custom rules
+func secretRules(custom string) ([]secretRule, error) {
+	rules := append([]secretRule{}, builtinSecretRules...)
+	for _, line := range strings.Split(custom, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || parts[1] == "" {
+			return nil, fmt.Errorf("rule '%s' is malformed, expected 'id=regexp'", line)
+		}
+		r, err := regexp.Compile(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("rule '%s' can not be used as a regexp", strings.TrimSpace(parts[0]))
+		}
+		rules = append(rules, secretRule{id: strings.TrimSpace(parts[0]), regexp: r})
+	}
+	return rules, nil
+}
+
+// scanLines applies all rules to the lines and returns every match. A secret matched by several rules is only
+// reported for the first one.
+func scanLines(lines []git.AddedLine, rules []secretRule, minEntropy float64) []secretMatch {
+	var matches []secretMatch
+	for _, l := range lines {
+		seen := map[int]bool{}
+		for _, r := range rules {
+			for _, loc := range r.regexp.FindAllStringSubmatchIndex(l.Text, -1) {
+				start, end := loc[0], loc[1]
+				if len(loc) >= 4 && loc[2] >= 0 {
+					start, end = loc[2], loc[3]
+				}
+				secret := l.Text[start:end]
+				if seen[start] || r.entropy && shannonEntropy(secret) < minEntropy {
+					continue
+				}
+				seen[start] = true
+				matches = append(matches, secretMatch{rule: r.id, secret: secret, line: l, column: start + 1})
+			}
+		}
+	}
+	return matches
+}
+
+// shannonEntropy returns the entropy of the string in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	var entropy float64
+	length := float64(len([]rune(s)))
+	for _, c := range counts {
+		p := float64(c) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// secretFingerprint identifies a secret without revealing it
+func secretFingerprint(rule string, secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return rule + ":" + hex.EncodeToString(sum[:])[:16]
+}
+
+// maskSecret keeps only the first characters of a secret. Characters are counted as runes in order to not split
+// multibyte characters.
+func maskSecret(secret string) string {
+	runes := []rune(secret)
+	visible := 4
+	if len(runes) <= 8 {
+		visible = 0
+	}
+	return string(runes[:visible]) + strings.Repeat("*", len(runes)-visible)
+}
+
+// readAllowlist reads the fingerprints of the allowlist file. Empty lines and comments starting with '#' are
+// ignored. A missing file results in an empty allowlist.
+func readAllowlist(file string) (map[string]bool, error) {
+	allowed := map[string]bool{}
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return allowed, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read allowlist: %+v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.SplitN(scanner.Text(), "#", 2)[0])
+		if line != "" {
+			allowed[line] = true
+		}
+	}
+	return allowed, scanner.Err()
+}
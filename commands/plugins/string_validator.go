@@ -1,40 +1,261 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
+	"github.com/jenpet/giks/git"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 )
 
 const (
-	varFailOnMismatch    = "FAIL_ON_MISMATCH"
-	varValidationPattern = "VALIDATION_PATTERN"
+	varFailOnMismatch     = "FAIL_ON_MISMATCH"
+	varValidationPattern  = "VALIDATION_PATTERN"
+	varValidationPatterns = "VALIDATION_PATTERNS"
+	varValidationMode     = "VALIDATION_MODE"
+	varValidationSource   = "VALIDATION_SOURCE"
+)
+
+const (
+	sourceCommitMsg  = "commit-msg"
+	sourceBranch     = "branch"
+	sourcePushedRefs = "pushed-refs"
+	sourceVarPrefix  = "var:"
+	sourceFilePrefix = "file:"
+
+	validationModeAll = "all"
+	validationModeAny = "any"
 )
 
 type StringValidator struct{}
 
+// validationPattern is a compiled pattern which either has to match or, in case it is negated, must not match
+type validationPattern struct {
+	raw     string
+	regexp  *regexp.Regexp
+	negated bool
+}
+
 func (sv StringValidator) ID() string {
 	return "string-validator"
 }
 
-func (sv StringValidator) Run(workingDir string, hook string, vars map[string]string, args []string) (bool, error) {
-	failOnMismatch, err := extractBoolVar(varFailOnMismatch, vars, false)
-	if err != nil {
-		return true, err
+func (sv StringValidator) Description() string {
+	return "Validates the commit message, branch, pushed refs, a variable or a file against regular expressions."
+}
+
+func (sv StringValidator) Hooks() []string {
+	return nil
+}
+
+func (sv StringValidator) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varValidationPattern, Type: VarTypeRegexp, Help: "regular expression the input has to match, prefix with '!' to negate it"},
+		{Name: varValidationPatterns, Type: VarTypeString, Help: "newline separated list of regular expressions which may contain spaces, prefix with '!' to negate a pattern"},
+		{Name: varValidationMode, Type: VarTypeString, Default: validationModeAll, Help: "either 'all' or 'any' patterns have to be satisfied"},
+		{Name: varValidationSource, Type: VarTypeString, Help: "input which is validated: 'commit-msg', 'branch', 'pushed-refs', 'var:NAME' or 'file:PATH'. " +
+			"Defaults to 'commit-msg' for message hooks and 'pushed-refs' for pre-push, pre-receive and update. Other hooks are skipped unless a source is set. Input which can not be read only warns"},
+		{Name: varFailOnMismatch, Type: VarTypeBool, Default: "false", Help: "fail instead of warn in case the input does not match"},
 	}
+}
 
-	pat, err := extractStringVar(varValidationPattern, vars, true)
+func (sv StringValidator) Run(ctx context.Context, in RunInput) Result {
+	failOnMismatch, err := in.Vars.Bool(varFailOnMismatch, false)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	patterns, err := validationPatterns(in.Vars)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	mode, err := in.Vars.String(varValidationMode, false)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	if mode == "" {
+		mode = validationModeAll
+	}
+	if mode != validationModeAll && mode != validationModeAny {
+		return Errorf("unknown validation mode '%s'. Supported modes are '%s' and '%s'", mode, validationModeAll, validationModeAny)
+	}
+	source, err := in.Vars.String(varValidationSource, false)
 	if err != nil {
-		return true, err
+		return Errorf("%s", err)
+	}
+	if source == "" {
+		if source = defaultValidationSource(in.Hook); source == "" {
+			return hookUnsupported(in.Hook, sv.ID())
+		}
+	}
+	if !knownValidationSource(source) {
+		return Errorf("unknown validation source '%s'", source)
+	}
+	inputs, err := validationInputs(source, in)
+	if err != nil {
+		// input which can not be read only warns
+		return Fail(false, err.Error())
+	}
+	if len(inputs) == 0 {
+		return Skip("no input for source '%s' available", source)
+	}
+
+	var findings []Finding
+	for _, input := range inputs {
+		if problems := validateString(input, patterns, mode); len(problems) > 0 {
+			for _, p := range problems {
+				findings = append(findings, Finding{Message: p})
+			}
+		}
+	}
+	if len(findings) > 0 {
+		return Fail(failOnMismatch, fmt.Sprintf("%s does not satisfy %s of the patterns", source, mode), findings...)
+	}
+	return Pass()
+}
+
+// validationPatterns compiles the single pattern and the pattern list. At least one pattern is required.
+func validationPatterns(vars Vars) ([]validationPattern, error) {
+	var raw []string
+	if p, err := vars.String(varValidationPattern, false); err != nil {
+		return nil, err
+	} else if strings.TrimSpace(p) != "" {
+		raw = append(raw, p)
+	}
+	for _, p := range strings.Split(vars[varValidationPatterns], "\n") {
+		if strings.TrimSpace(p) != "" {
+			raw = append(raw, strings.TrimSpace(p))
+		}
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("either '%s' or '%s' is required", varValidationPattern, varValidationPatterns)
 	}
+	patterns := make([]validationPattern, len(raw))
+	for i, p := range raw {
+		patterns[i].raw = p
+		if strings.HasPrefix(p, "!") {
+			patterns[i].negated = true
+			p = strings.TrimPrefix(p, "!")
+		}
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("provided pattern '%s' can not be used as a regexp", p)
+		}
+		patterns[i].regexp = r
+	}
+	return patterns, nil
+}
+
+// defaultValidationSource returns the source validated by the hook in case none is configured or an empty string in
+// case the hook does not have an obvious input
+func defaultValidationSource(hook string) string {
 	switch hook {
-	// TODO: need re-work. In the current setup it is only usable for a commit-msg hook
-	case "commit-msg":
-		b, err := os.ReadFile(args[0])
+	case git.HookCommitMsg, git.HookPrepareCommitMsg:
+		return sourceCommitMsg
+	case git.HookPrePush, git.HookPreReceive, git.HookUpdate:
+		return sourcePushedRefs
+	}
+	return ""
+}
+
+// knownValidationSource returns whether the source is supported
+func knownValidationSource(source string) bool {
+	switch source {
+	case sourceCommitMsg, sourceBranch, sourcePushedRefs:
+		return true
+	}
+	return strings.HasPrefix(source, sourceVarPrefix) || strings.HasPrefix(source, sourceFilePrefix)
+}
+
+// validationInputs returns all strings which have to be validated for the given source
+func validationInputs(source string, in RunInput) ([]string, error) {
+	switch {
+	case source == sourceCommitMsg:
+		if len(in.Args) == 0 {
+			return nil, fmt.Errorf("source '%s' requires a commit message file", source)
+		}
+		b, err := os.ReadFile(in.Args[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not read file '%s': %+v", in.Args[0], err)
+		}
+		return []string{string(b)}, nil
+	case source == sourceBranch:
+		branch, err := in.Repo.CurrentBranch()
+		if err != nil {
+			// detached HEAD states do not have a branch to validate
+			return nil, nil
+		}
+		return []string{branch}, nil
+	case source == sourcePushedRefs:
+		return pushedRefs(in)
+	case strings.HasPrefix(source, sourceVarPrefix):
+		name := strings.TrimPrefix(source, sourceVarPrefix)
+		if val, ok := in.Vars[name]; ok {
+			return []string{val}, nil
+		}
+		return nil, fmt.Errorf("variable '%s' of source '%s' is not set", name, source)
+	case strings.HasPrefix(source, sourceFilePrefix):
+		file := strings.TrimPrefix(source, sourceFilePrefix)
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(in.WorkingDir(), file)
+		}
+		b, err := os.ReadFile(file)
 		if err != nil {
-			return false, fmt.Errorf("could not read file '%s'", err)
+			return nil, fmt.Errorf("could not read file '%s': %+v", file, err)
+		}
+		return []string{string(b)}, nil
+	}
+	return nil, fmt.Errorf("unknown validation source '%s'", source)
+}
+
+// pushedRefs returns the names of the refs which get updated. Deleted refs are omitted.
+func pushedRefs(in RunInput) ([]string, error) {
+	if in.Hook == git.HookUpdate {
+		if len(in.Args) < 3 {
+			return nil, fmt.Errorf("hook '%s' requires the ref name, old and new object name", in.Hook)
+		}
+		if git.IsZeroSHA(in.Args[2]) {
+			return nil, nil
+		}
+		return []string{in.Args[0]}, nil
+	}
+	if !git.HasRefUpdates(in.Hook) || in.Stdin == nil {
+		return nil, fmt.Errorf("hook '%s' does not provide pushed refs", in.Hook)
+	}
+	b, err := io.ReadAll(in.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("could not read ref updates: %+v", err)
+	}
+	updates, err := git.ParseRefUpdates(in.Hook, string(b))
+	if err != nil {
+		return nil, err
+	}
+	var refs []string
+	for _, u := range updates {
+		if !u.Deletion && u.RemoteRef != "" {
+			refs = append(refs, u.RemoteRef)
 		}
-		return failOnMismatch, matchString(string(b), pat)
-	default:
-		return hookUnsupported(hook, sv.ID())
 	}
+	return refs, nil
+}
+
+// validateString checks the input against all patterns and returns the problems in case the patterns are not
+// satisfied according to the mode.
+func validateString(input string, patterns []validationPattern, mode string) []string {
+	var problems []string
+	for _, p := range patterns {
+		matches := p.regexp.MatchString(input)
+		switch {
+		case p.negated && matches:
+			problems = append(problems, fmt.Sprintf("input '%s' matches forbidden pattern '%s'", input, strings.TrimPrefix(p.raw, "!")))
+		case !p.negated && !matches:
+			problems = append(problems, fmt.Sprintf("input '%s' does not match required pattern '%s'", input, p.raw))
+		}
+	}
+	if mode == validationModeAny && len(problems) < len(patterns) {
+		return nil
+	}
+	return problems
 }
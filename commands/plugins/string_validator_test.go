@@ -0,0 +1,79 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+func TestStringValidator(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	_, _ = tr.Command("checkout", "-b", "feature/GIKS-1-validation")
+	tr.WriteFile("msg.txt", "FEAT: validate strings")
+
+	validatorTests := []struct {
+		name           string
+		hook           string
+		vars           map[string]string
+		args           []string
+		stdin          string
+		statusExpected Status
+	}{
+		{"commit message matches", git.HookCommitMsg, map[string]string{"VALIDATION_PATTERN": "^FEAT: .+"},
+			[]string{filepath.Join(tr.AbsDir(), "msg.txt")}, "", StatusPass},
+		{"commit message mismatch warns", git.HookCommitMsg, map[string]string{"VALIDATION_PATTERN": "^FIX: .+"},
+			[]string{filepath.Join(tr.AbsDir(), "msg.txt")}, "", StatusWarn},
+		{"no default source", git.HookPreCommit, map[string]string{"VALIDATION_PATTERN": "^bugfix/"}, nil, "", StatusSkip},
+		{"branch matches", git.HookPreCommit, map[string]string{"VALIDATION_PATTERN": "^feature/GIKS-[0-9]+", "VALIDATION_SOURCE": "branch"},
+			nil, "", StatusPass},
+		{"branch matches forbidden pattern", git.HookPreCommit,
+			map[string]string{"VALIDATION_PATTERNS": "^feature/\n!validation", "VALIDATION_SOURCE": "branch", "FAIL_ON_MISMATCH": "true"},
+			nil, "", StatusFail},
+		{"branch satisfies any pattern", git.HookPreCommit,
+			map[string]string{"VALIDATION_PATTERNS": "^bugfix/\n^feature/", "VALIDATION_SOURCE": "branch", "VALIDATION_MODE": "any", "FAIL_ON_MISMATCH": "true"},
+			nil, "", StatusPass},
+		{"branch satisfies no pattern", git.HookPreCommit,
+			map[string]string{"VALIDATION_PATTERNS": "^bugfix/\n^hotfix/", "VALIDATION_SOURCE": "branch", "VALIDATION_MODE": "any", "FAIL_ON_MISMATCH": "true"},
+			nil, "", StatusFail},
+		{"pushed refs", git.HookPrePush,
+			map[string]string{"VALIDATION_PATTERN": "^refs/heads/(main|feature/.+)$", "FAIL_ON_MISMATCH": "true"},
+			nil, "refs/heads/wip abc refs/heads/wip " + zeroSHA + "\n", StatusFail},
+		{"deleted refs are ignored", git.HookPrePush,
+			map[string]string{"VALIDATION_PATTERN": "^refs/heads/main$", "FAIL_ON_MISMATCH": "true"},
+			nil, "(delete) " + zeroSHA + " refs/heads/wip abc\n", StatusSkip},
+		{"updated tag", git.HookUpdate,
+			map[string]string{"VALIDATION_PATTERN": "^refs/tags/v[0-9]+\\.[0-9]+\\.[0-9]+$", "FAIL_ON_MISMATCH": "true"},
+			[]string{"refs/tags/v1.2", zeroSHA, "abc"}, "", StatusFail},
+		{"variable source", git.HookPreCommit,
+			map[string]string{"VALIDATION_PATTERN": "^staged\\.go$", "VALIDATION_SOURCE": "var:GIKS_MIXIN_STAGED_FILES", "GIKS_MIXIN_STAGED_FILES": "staged.go"},
+			nil, "", StatusPass},
+		{"file source", git.HookPreCommit,
+			map[string]string{"VALIDATION_PATTERN": "^FEAT", "VALIDATION_SOURCE": "file:msg.txt"},
+			nil, "", StatusPass},
+		{"unreadable file source warns", git.HookPreCommit,
+			map[string]string{"VALIDATION_PATTERN": "^FEAT", "VALIDATION_SOURCE": "file:absent.txt", "FAIL_ON_MISMATCH": "true"},
+			nil, "", StatusWarn},
+		{"unset variable source warns", git.HookPreCommit,
+			map[string]string{"VALIDATION_PATTERN": ".*", "VALIDATION_SOURCE": "var:GIKS_ABSENT"},
+			nil, "", StatusWarn},
+		{"unknown source", git.HookPreCommit,
+			map[string]string{"VALIDATION_PATTERN": ".*", "VALIDATION_SOURCE": "tags"},
+			nil, "", StatusError},
+		{"missing pattern", git.HookPreCommit, map[string]string{}, nil, "", StatusError},
+	}
+	for _, tt := range validatorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := testInput(tt.hook, tt.vars, tt.args)
+			in.Repo = git.NewRepository(tr.AbsDir())
+			in.Stdin = strings.NewReader(tt.stdin)
+			res := StringValidator{}.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match validation result: %s", res)
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	varSyntaxExtensions    = "SYNTAX_VALIDATOR_EXTENSIONS"
+	varSyntaxDuplicateKeys = "SYNTAX_VALIDATOR_DUPLICATE_KEYS"
+	varSyntaxExcludes      = "SYNTAX_VALIDATOR_EXCLUDES"
+)
+
+const (
+	syntaxJSON = "json"
+	syntaxYAML = "yaml"
+	syntaxXML  = "xml"
+	syntaxNone = "none"
+)
+
+// syntaxValidators validate the content of a file and report a finding for every problem. Duplicate keys are only
+// reported if requested.
+var syntaxValidators = map[string]func(file string, content string, duplicateKeys bool) []Finding{
+	syntaxJSON: validateJSON,
+	syntaxYAML: validateYAML,
+	syntaxXML:  validateXML,
+}
+
+// defaultSyntaxExtensions maps file extensions to the syntax their files are validated against
+var defaultSyntaxExtensions = map[string]string{".json": syntaxJSON, ".yml": syntaxYAML, ".yaml": syntaxYAML, ".xml": syntaxXML}
+
+// yamlErrorRegex extracts the line from the errors of the YAML parser, e.g. 'yaml: line 3: did not find expected key'
+var yamlErrorRegex = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// SyntaxValidator validates the syntax of staged JSON, YAML and XML files
+type SyntaxValidator struct{}
+
+func (sv SyntaxValidator) ID() string {
+	return "syntax-validator"
+}
+
+func (sv SyntaxValidator) Description() string {
+	return "Validates the syntax of staged JSON, YAML and XML files read from the index and optionally detects duplicate keys."
+}
+
+func (sv SyntaxValidator) Hooks() []string {
+	return []string{git.HookPreCommit}
+}
+
+func (sv SyntaxValidator) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varSyntaxExtensions, Type: VarTypeList,
+			Help: "space separated list of '.ext=syntax' entries adding or overriding the syntax of file extensions, e.g. '.jsonc=json .tpl=yaml .xml=none'"},
+		{Name: varSyntaxDuplicateKeys, Type: VarTypeBool, Default: "true", Help: "report duplicate keys within JSON objects and YAML mappings"},
+		{Name: varSyntaxExcludes, Type: VarTypeList, Help: "space separated list of globs of files which are not validated, e.g. 'testdata/'"},
+	}
+}
+
+func (sv SyntaxValidator) Run(ctx context.Context, in RunInput) Result {
+	if in.Hook != git.HookPreCommit {
+		return hookUnsupported(in.Hook, sv.ID())
+	}
+	extensions, err := syntaxExtensions(in.Vars.List(varSyntaxExtensions))
+	if err != nil {
+		return Errorf("variable '%s' is invalid: %s", varSyntaxExtensions, err)
+	}
+	duplicateKeys := true
+	if _, ok := in.Vars[varSyntaxDuplicateKeys]; ok {
+		if duplicateKeys, err = in.Vars.Bool(varSyntaxDuplicateKeys, false); err != nil {
+			return Errorf("%s", err)
+		}
+	}
+	blobs, contents, err := stagedContents(in, in.Vars.List(varSyntaxExcludes))
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	var findings []Finding
+	for i, b := range blobs {
+		validate, ok := syntaxValidators[extensions[strings.ToLower(path.Ext(b.Path))]]
+		if !ok {
+			continue
+		}
+		findings = append(findings, validate(b.Path, contents[i], duplicateKeys)...)
+	}
+	if len(findings) > 0 {
+		return Fail(true, "staged files contain syntax errors", findings...)
+	}
+	return Pass()
+}
+
+// syntaxExtensions returns the default extensions extended by the '.ext=syntax' entries
+func syntaxExtensions(entries []string) (map[string]string, error) {
+	extensions := map[string]string{}
+	for ext, syntax := range defaultSyntaxExtensions {
+		extensions[ext] = syntax
+	}
+	for _, e := range entries {
+		parts := strings.SplitN(e, "=", 2)
+		ext := strings.ToLower(parts[0])
+		if len(parts) != 2 || !strings.HasPrefix(ext, ".") {
+			return nil, fmt.Errorf("entry '%s' is malformed, expected '.ext=syntax'", e)
+		}
+		syntax := strings.ToLower(parts[1])
+		if _, ok := syntaxValidators[syntax]; !ok && syntax != syntaxNone {
+			return nil, fmt.Errorf("unknown syntax '%s'. Supported syntaxes are '%s', '%s', '%s' and '%s'", syntax, syntaxJSON, syntaxYAML, syntaxXML, syntaxNone)
+		}
+		extensions[ext] = syntax
+	}
+	return extensions, nil
+}
+
+// validateJSON reports the first syntax error or all duplicate keys of objects
+func validateJSON(file string, content string, duplicateKeys bool) []Finding {
+	var v interface{}
+	if err := json.Unmarshal([]byte(content), &v); err != nil {
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			return []Finding{{File: file, Message: err.Error()}}
+		}
+		// the offset points behind the byte which caused the error
+		line, column := offsetPosition(content, int(syntaxErr.Offset)-1)
+		return []Finding{{File: file, Line: line, Column: column, Message: syntaxErr.Error()}}
+	}
+	if !duplicateKeys {
+		return nil
+	}
+	var findings []Finding
+	dec := json.NewDecoder(strings.NewReader(content))
+	// every open object or array holds the keys seen so far, arrays hold nil
+	var keys []map[string]int
+	expectKey := false
+	for {
+		offset := int(dec.InputOffset())
+		t, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t {
+		case json.Delim('{'):
+			keys = append(keys, map[string]int{})
+			expectKey = true
+			continue
+		case json.Delim('['):
+			keys = append(keys, nil)
+		case json.Delim('}'), json.Delim(']'):
+			keys = keys[:len(keys)-1]
+		default:
+			if key, ok := t.(string); ok && expectKey {
+				start := offset + len(content[offset:]) - len(strings.TrimLeft(content[offset:], " \t\r\n,"))
+				line, column := offsetPosition(content, start)
+				if first, ok := keys[len(keys)-1][key]; ok {
+					findings = append(findings, Finding{File: file, Line: line, Column: column,
+						Message: fmt.Sprintf("duplicate key '%s', first defined in line %d", key, first)})
+				} else {
+					keys[len(keys)-1][key] = line
+				}
+				expectKey = false
+				continue
+			}
+		}
+		// the next token is a key in case the value within an object is complete
+		expectKey = len(keys) > 0 && keys[len(keys)-1] != nil
+	}
+	return findings
+}
+
+// validateYAML reports all duplicate keys of mappings and the first syntax error of all documents
+func validateYAML(file string, content string, duplicateKeys bool) []Finding {
+	dec := yaml.NewDecoder(strings.NewReader(content))
+	var findings []Finding
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			return findings
+		}
+		if err != nil {
+			f := Finding{File: file, Message: err.Error()}
+			if m := yamlErrorRegex.FindStringSubmatch(err.Error()); m != nil {
+				f.Line, _ = strconv.Atoi(m[1])
+				f.Column = yamlErrorColumn(content, f.Line, m[2])
+				f.Message = "yaml: " + m[2]
+			}
+			return append(findings, f)
+		}
+		if duplicateKeys {
+			findings = append(findings, duplicateYAMLKeys(file, &doc)...)
+		}
+	}
+}
+
+// yamlErrorColumn derives the column of a syntax error since the parser only reports its line. The line is scanned
+// for the token the error refers to and falls back to the first non-blank character.
+func yamlErrorColumn(content string, line int, msg string) int {
+	lines := strings.Split(content, "\n")
+	if line < 1 || line > len(lines) {
+		return 0
+	}
+	text := strings.TrimSuffix(lines[line-1], "\r")
+	index := -1
+	switch {
+	case strings.Contains(msg, "tab") || strings.Contains(msg, "cannot start any token"):
+		index = strings.IndexAny(text, "\t@`")
+	case strings.Contains(msg, "mapping values are not allowed"):
+		if index = strings.Index(text, ": "); index < 0 && strings.HasSuffix(text, ":") {
+			index = len(text) - 1
+		}
+	}
+	if index < 0 {
+		index = len(text) - len(strings.TrimLeft(text, " \t"))
+	}
+	return utf8.RuneCountInString(text[:index]) + 1
+}
+
+// duplicateYAMLKeys reports keys which are defined more than once within the same mapping in document order. Merge
+// keys are ignored.
+func duplicateYAMLKeys(file string, node *yaml.Node) []Finding {
+	var findings []Finding
+	seen := map[string]int{}
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 && child.Kind == yaml.ScalarNode && child.Tag != "!!merge" {
+			if first, ok := seen[child.Value]; ok {
+				findings = append(findings, Finding{File: file, Line: child.Line, Column: child.Column,
+					Message: fmt.Sprintf("duplicate key '%s', first defined in line %d", child.Value, first)})
+			} else {
+				seen[child.Value] = child.Line
+			}
+		}
+		findings = append(findings, duplicateYAMLKeys(file, child)...)
+	}
+	return findings
+}
+
+// validateXML reports the first syntax error. Besides the syntax of the tokens the document has to consist of a
+// single root element.
+func validateXML(file string, content string, _ bool) []Finding {
+	dec := xml.NewDecoder(strings.NewReader(content))
+	// only the syntax is validated, hence the content does not have to be decoded
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) { return input, nil }
+	depth, roots := 0, 0
+	fail := func(msg string) []Finding {
+		line, column := offsetPosition(content, int(dec.InputOffset()))
+		return []Finding{{File: file, Line: line, Column: column, Message: msg}}
+	}
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(err.Error())
+		}
+		switch tt := t.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				if roots++; roots > 1 {
+					return fail(fmt.Sprintf("element <%s> is a second root element", tt.Name.Local))
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(tt)) > 0 {
+				return fail("text outside of the root element")
+			}
+		}
+	}
+	if roots == 0 {
+		return []Finding{{File: file, Message: "document has no root element"}}
+	}
+	return nil
+}
+
+// offsetPosition converts a byte offset into a line and column which both start at 1
+func offsetPosition(content string, offset int) (int, int) {
+	if offset > len(content) {
+		offset = len(content)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	before := content[:offset]
+	return strings.Count(before, "\n") + 1, offset - strings.LastIndex(before, "\n")
+}
@@ -0,0 +1,82 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyntaxValidators(t *testing.T) {
+	syntaxTests := []struct {
+		name              string
+		syntax            string
+		content           string
+		locationsExpected []string
+	}{
+		{"valid json", syntaxJSON, "{\"a\": [1, {\"a\": 2}], \"b\": {}}", nil},
+		{"invalid json", syntaxJSON, "{\n  \"a\": 1,\n  \"b\": }\n", []string{"f:3:8"}},
+		{"truncated json", syntaxJSON, "{\"a\": [1,", []string{"f:1:9"}},
+		{"duplicate json keys", syntaxJSON, "{\n  \"a\": 1,\n  \"b\": {\"a\": 1, \"a\": 2},\n  \"a\": [{\"x\": 1}, {\"x\": 1}]\n}", []string{"f:3:17", "f:4:3"}},
+		{"valid yaml", syntaxYAML, "a: 1\nb:\n  - c: 2\n", nil},
+		{"empty yaml", syntaxYAML, "", nil},
+		{"invalid yaml", syntaxYAML, "a: 1\n  b: 2\n", []string{"f:2:4"}},
+		{"invalid token", syntaxYAML, "a: 1\nb: @c\n", []string{"f:2:4"}},
+		{"invalid second document", syntaxYAML, "a: 1\na: 2\n---\nb:\n\t- c\n", []string{"f:2:1", "f:5:1"}},
+		{"duplicate yaml keys", syntaxYAML, "a: 1\nb:\n  c: 1\n  c: 2\na: 2\n---\na: 1\n", []string{"f:4:3", "f:5:1"}},
+		{"merge keys", syntaxYAML, "base: &b\n  x: 1\nchild:\n  <<: *b\n  <<: *b\n", nil},
+		{"valid xml", syntaxXML, "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<a x=\"1\"><b/></a>\n", nil},
+		{"unclosed xml element", syntaxXML, "<a>\n  <b>\n</a>\n", []string{"f:3:5"}},
+		{"multiple xml roots", syntaxXML, "<a/>\n<b/>\n", []string{"f:2:5"}},
+		{"empty xml", syntaxXML, "", []string{"f"}},
+	}
+	for _, tt := range syntaxTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var locations []string
+			for _, f := range syntaxValidators[tt.syntax]("f", tt.content, true) {
+				locations = append(locations, strings.SplitN(f.String(), ": ", 2)[0])
+			}
+			assert.Equal(t, tt.locationsExpected, locations, "expected problems should be reported")
+		})
+	}
+}
+
+func TestSyntaxValidator(t *testing.T) {
+	validatorTests := []struct {
+		name           string
+		vars           map[string]string
+		statusExpected Status
+		filesExpected  []string
+	}{
+		{"defaults", map[string]string{}, StatusFail, []string{"broken.json", "dup.yaml"}},
+		{"without duplicate keys", map[string]string{"SYNTAX_VALIDATOR_DUPLICATE_KEYS": "false"}, StatusFail, []string{"broken.json"}},
+		{"custom extensions", map[string]string{"SYNTAX_VALIDATOR_EXTENSIONS": ".tpl=json .json=none"}, StatusFail, []string{"dup.yaml", "template.tpl"}},
+		{"excludes", map[string]string{"SYNTAX_VALIDATOR_EXCLUDES": "*.json *.yaml"}, StatusPass, nil},
+		{"unknown syntax", map[string]string{"SYNTAX_VALIDATOR_EXTENSIONS": ".tpl=toml"}, StatusError, nil},
+	}
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("broken.json", "{")
+	tr.WriteFile("dup.yaml", "a: 1\na: 2\n")
+	tr.WriteFile("template.tpl", "{{ .value }}")
+	tr.AddAll()
+	// the working tree is not validated
+	tr.WriteFile("dup.yaml", "a: 1\n")
+
+	sv, _ := Get("syntax-validator")
+	for _, tt := range validatorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := testInput(git.HookPreCommit, tt.vars, nil)
+			in.Repo = git.NewRepository(tr.AbsDir())
+			res := sv.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match result: %s", res)
+			var files []string
+			for _, f := range res.Findings {
+				files = append(files, f.File)
+			}
+			assert.ElementsMatch(t, tt.filesExpected, files, "expected files should be reported")
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	varTicketPattern     = "TICKET_ID_PATTERN"
+	varTicketTemplate    = "TICKET_ID_TEMPLATE"
+	varTicketPosition    = "TICKET_ID_POSITION"
+	varTicketSkipSources = "TICKET_ID_SKIP_SOURCES"
+	varTicketRequired    = "TICKET_ID_REQUIRED"
+)
+
+const (
+	defaultTicketPattern     = `[A-Z][A-Z0-9]+-[0-9]+`
+	defaultTicketSkipSources = "merge squash"
+
+	ticketPositionPrepend = "prepend"
+	ticketPositionAppend  = "append"
+
+	placeholderID = "{id}"
+)
+
+// defaultTicketTemplates holds the template used for each position in case none is configured
+var defaultTicketTemplates = map[string]string{
+	ticketPositionPrepend: placeholderID + ": ",
+	ticketPositionAppend:  placeholderID,
+}
+
+// TicketID injects the ticket ID found within the name of the current branch into the commit message
+type TicketID struct{}
+
+func (ti TicketID) ID() string {
+	return "ticket-id"
+}
+
+func (ti TicketID) Description() string {
+	return "Prepends or appends the ticket ID extracted from the current branch name to the commit message."
+}
+
+func (ti TicketID) Hooks() []string {
+	return []string{git.HookPrepareCommitMsg}
+}
+
+func (ti TicketID) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varTicketPattern, Type: VarTypeRegexp, Default: defaultTicketPattern,
+			Help: "regular expression extracting the ticket ID from the branch name. The first capture group is used if present"},
+		{Name: varTicketTemplate, Type: VarTypeString,
+			Help: "text inserted into the message, {id} is replaced by the ticket ID. Defaults to '{id}: ' when prepending and '{id}' when appending"},
+		{Name: varTicketPosition, Type: VarTypeString, Default: ticketPositionPrepend, Help: "either 'prepend' to the subject or 'append' as last paragraph"},
+		{Name: varTicketSkipSources, Type: VarTypeList, Default: defaultTicketSkipSources,
+			Help: "commit message sources which are left untouched: message, template, merge, squash or commit"},
+		{Name: varTicketRequired, Type: VarTypeBool, Default: "false", Help: "fail in case the branch name does not contain a ticket ID"},
+	}
+}
+
+func (ti TicketID) Run(ctx context.Context, in RunInput) Result {
+	if in.Hook != git.HookPrepareCommitMsg {
+		return hookUnsupported(in.Hook, ti.ID())
+	}
+	if len(in.Args) == 0 {
+		return Errorf("no commit message file provided")
+	}
+	source := ""
+	if len(in.Args) > 1 {
+		source = in.Args[1]
+	}
+	skipSources := strings.Fields(defaultTicketSkipSources)
+	if _, ok := in.Vars[varTicketSkipSources]; ok {
+		skipSources = in.Vars.List(varTicketSkipSources)
+	}
+	if source != "" && contains(skipSources, source) {
+		return Skip("commit message source '%s' is skipped", source)
+	}
+
+	pattern := in.Vars[varTicketPattern]
+	if strings.TrimSpace(pattern) == "" {
+		pattern = defaultTicketPattern
+	}
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return Errorf("provided pattern '%s' can not be used as a regexp", pattern)
+	}
+	position := in.Vars[varTicketPosition]
+	if position == "" {
+		position = ticketPositionPrepend
+	}
+	template := in.Vars[varTicketTemplate]
+	if template == "" {
+		template = defaultTicketTemplates[position]
+	}
+	if position != ticketPositionPrepend && position != ticketPositionAppend {
+		return Errorf("unknown position '%s'. Supported positions are '%s' and '%s'", position, ticketPositionPrepend, ticketPositionAppend)
+	}
+	if !strings.Contains(template, placeholderID) {
+		return Errorf("template '%s' does not contain the placeholder '%s'", template, placeholderID)
+	}
+	required, err := in.Vars.Bool(varTicketRequired, false)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+
+	branch, err := in.Repo.CurrentBranch()
+	if err != nil {
+		return Skip("no branch checked out")
+	}
+	id := ticketIDFromBranch(branch, r)
+	if id == "" {
+		if required {
+			return Fail(true, fmt.Sprintf("branch '%s' does not contain a ticket ID matching '%s'", branch, pattern))
+		}
+		return Skip("branch '%s' does not contain a ticket ID", branch)
+	}
+
+	b, err := os.ReadFile(in.Args[0])
+	if err != nil {
+		return Errorf("could not read commit message file: %+v", err)
+	}
+	msg := string(b)
+	// the message already references the ticket, e.g. when amending
+	if regexp.MustCompile(`(^|\W)` + regexp.QuoteMeta(id) + `(\W|$)`).MatchString(cleanCommitMessage(msg)) {
+		return Pass()
+	}
+	text := strings.ReplaceAll(template, placeholderID, id)
+	if err = os.WriteFile(in.Args[0], []byte(insertIntoMessage(msg, text, position)), 0644); err != nil {
+		return Errorf("could not write commit message file: %+v", err)
+	}
+	return Pass()
+}
+
+// ticketIDFromBranch returns the first capture group of the pattern or the whole match in case there is no group
+func ticketIDFromBranch(branch string, r *regexp.Regexp) string {
+	m := r.FindStringSubmatch(branch)
+	switch {
+	case m == nil:
+		return ""
+	case len(m) > 1:
+		return m[1]
+	}
+	return m[0]
+}
+
+// insertIntoMessage prepends the text to the subject or appends it as a separate paragraph after the last line of
+// the message. Comments git adds to the message are kept in place.
+func insertIntoMessage(msg string, text string, position string) string {
+	lines := strings.Split(msg, "\n")
+	first, last := -1, -1
+	for i, line := range lines {
+		if line == scissorsLine {
+			break
+		}
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+	switch {
+	case first < 0 && strings.TrimSpace(lines[0]) == "":
+		// empty message: the text becomes the subject
+		lines[0] = text
+	case first < 0:
+		lines = append([]string{text}, lines...)
+	case position == ticketPositionPrepend:
+		lines[first] = text + lines[first]
+	default:
+		tail := append([]string{"", text}, lines[last+1:]...)
+		lines = append(lines[:last+1], tail...)
+	}
+	return strings.Join(lines, "\n")
+}
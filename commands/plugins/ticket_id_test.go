@@ -0,0 +1,56 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTicketID(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	_, _ = tr.Command("checkout", "-b", "feature/ABC-123-foo")
+
+	ticketTests := []struct {
+		name           string
+		msg            string
+		source         string
+		vars           map[string]string
+		statusExpected Status
+		msgExpected    string
+	}{
+		{"prepend to message", "add foo\n", "message", map[string]string{}, StatusPass, "ABC-123: add foo\n"},
+		{"prepend to empty message", "\n# Please enter the commit message\n", "", map[string]string{}, StatusPass,
+			"ABC-123: \n# Please enter the commit message\n"},
+		{"append before comments", "add foo\n\nbody\n# comment\n", "", map[string]string{"TICKET_ID_POSITION": "append", "TICKET_ID_TEMPLATE": "Refs: {id}"},
+			StatusPass, "add foo\n\nbody\n\nRefs: ABC-123\n# comment\n"},
+		{"idempotent when amending", "ABC-123: add foo\n", "commit", map[string]string{}, StatusPass, "ABC-123: add foo\n"},
+		{"similar ticket is no reference", "ABC-1234: add foo\n", "message", map[string]string{}, StatusPass, "ABC-123: ABC-1234: add foo\n"},
+		{"merge is skipped", "Merge branch 'main'\n", "merge", map[string]string{}, StatusSkip, "Merge branch 'main'\n"},
+		{"capture group", "add foo\n", "message", map[string]string{"TICKET_ID_PATTERN": `feature/[A-Z]+-([0-9]+)`, "TICKET_ID_TEMPLATE": "[#{id}] "},
+			StatusPass, "[#123] add foo\n"},
+		{"no ticket required", "add foo\n", "message", map[string]string{"TICKET_ID_PATTERN": "XYZ-[0-9]+", "TICKET_ID_REQUIRED": "true"},
+			StatusFail, "add foo\n"},
+		{"template without placeholder", "add foo\n", "message", map[string]string{"TICKET_ID_TEMPLATE": "ticket: "}, StatusError, "add foo\n"},
+	}
+	ti, _ := Get("ticket-id")
+	for _, tt := range ticketTests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+			_ = os.WriteFile(file, []byte(tt.msg), 0644)
+			args := []string{file}
+			if tt.source != "" {
+				args = append(args, tt.source)
+			}
+			in := testInput(git.HookPrepareCommitMsg, tt.vars, args)
+			in.Repo = git.NewRepository(tr.AbsDir())
+			res := ti.Run(context.Background(), in)
+			assert.Equal(t, tt.statusExpected, res.Status, "expected status does not match result: %s", res)
+			b, _ := os.ReadFile(file)
+			assert.Equal(t, tt.msgExpected, string(b), "commit message does not match expected one")
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package plugins
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Vars holds the variables of a plugin run which consist of the giks variables and the configured plugin variables
+type Vars map[string]string
+
+// MergeVars merges the configured plugin variables into the variables provided by giks. In case the value of a
+// plugin variable is the name of a giks variable it gets replaced by the value of the giks variable.
+func MergeVars(vars map[string]string, pluginVars map[string]string) Vars {
+	for k, v := range pluginVars {
+		if val, ok := vars[strings.TrimSpace(v)]; ok {
+			vars[k] = val
+			continue
+		}
+		vars[k] = v
+	}
+	return vars
+}
+
+// String returns the value of the variable. Required variables must neither be absent nor empty.
+func (v Vars) String(key string, required bool) (string, error) {
+	var str string
+	err := v.extract(key, func(val string) error {
+		str = val
+		return nil
+	}, required)
+	return str, err
+}
+
+// Bool returns the parsed boolean value of the variable. Absent optional variables are false.
+func (v Vars) Bool(key string, required bool) (bool, error) {
+	var b bool
+	err := v.extract(key, func(val string) error {
+		parsed, err := strconv.ParseBool(val)
+		b = parsed
+		return err
+	}, required)
+	return b, err
+}
+
+// Int returns the parsed integer value of the variable. Absent optional variables are zero.
+func (v Vars) Int(key string, required bool) (int, error) {
+	var i int
+	err := v.extract(key, func(val string) error {
+		parsed, err := strconv.Atoi(strings.TrimSpace(val))
+		i = parsed
+		return err
+	}, required)
+	return i, err
+}
+
+// Float returns the parsed floating point value of the variable. Absent optional variables are 0.
+func (v Vars) Float(key string, required bool) (float64, error) {
+	var f float64
+	err := v.extract(key, func(val string) error {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		f = parsed
+		return err
+	}, required)
+	return f, err
+}
+
+// List returns the space separated elements of the variable
+func (v Vars) List(key string) []string {
+	return strings.Fields(v[key])
+}
+
+func (v Vars) extract(key string, parseFunc func(val string) error, required bool) error {
+	if val, ok := v[key]; ok {
+		if strings.TrimSpace(val) == "" {
+			if required {
+				return fmt.Errorf("variable '%s' is required but empty", key)
+			}
+			return nil
+		}
+		if err := parseFunc(val); err != nil {
+			return fmt.Errorf("failed parsing '%s' variable", key)
+		}
+	} else if required {
+		return fmt.Errorf("variable '%s' is required but not set", key)
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"github.com/jenpet/giks/git"
+	"strings"
+)
+
+const (
+	varWhitespaceChecks     = "WHITESPACE_CHECKS"
+	varWhitespaceIncludes   = "WHITESPACE_INCLUDES"
+	varWhitespaceExcludes   = "WHITESPACE_EXCLUDES"
+	varWhitespaceFix        = "WHITESPACE_FIX"
+	varWhitespaceLineEnding = "WHITESPACE_LINE_ENDING"
+	varWhitespaceIndent     = "WHITESPACE_INDENT"
+	varWhitespaceTabWidth   = "WHITESPACE_TAB_WIDTH"
+)
+
+const (
+	checkTrailingWhitespace = "trailing-whitespace"
+	checkFinalNewline       = "final-newline"
+	checkBlankLinesEOF      = "blank-lines-eof"
+	checkLineEndings        = "line-endings"
+	checkIndentation        = "indentation"
+
+	lineEndingAuto = "auto"
+	lineEndingLF   = "lf"
+	lineEndingCRLF = "crlf"
+
+	indentSpaces = "spaces"
+	indentTabs   = "tabs"
+
+	defaultTabWidth = 4
+)
+
+var whitespaceChecks = []string{checkTrailingWhitespace, checkFinalNewline, checkBlankLinesEOF, checkLineEndings, checkIndentation}
+
+// Whitespace detects and optionally fixes whitespace problems within the staged text files
+type Whitespace struct{}
+
+// whitespaceRules holds the configured checks
+type whitespaceRules struct {
+	checks     []string
+	lineEnding string
+	indent     string
+	tabWidth   int
+}
+
+// textLine is a single line of a file without its line ending
+type textLine struct {
+	text string
+	crlf bool
+}
+
+func (ws Whitespace) ID() string {
+	return "whitespace"
+}
+
+func (ws Whitespace) Description() string {
+	return "Detects and optionally fixes trailing whitespace, final newlines, blank lines at EOF, line endings and indentation of staged files."
+}
+
+func (ws Whitespace) Hooks() []string {
+	return []string{git.HookPreCommit}
+}
+
+func (ws Whitespace) Vars() []VarSpec {
+	return []VarSpec{
+		{Name: varWhitespaceChecks, Type: VarTypeList, Default: strings.Join(whitespaceChecks, " "), Help: "space separated list of enabled checks"},
+		{Name: varWhitespaceIncludes, Type: VarTypeList, Help: "space separated list of globs of files which are checked, all files are checked if empty"},
+		{Name: varWhitespaceExcludes, Type: VarTypeList, Help: "space separated list of globs of files which are not checked"},
+		{Name: varWhitespaceFix, Type: VarTypeBool, Default: "false", Help: "fix the problems within the index and the working tree instead of failing"},
+		{Name: varWhitespaceLineEnding, Type: VarTypeString, Default: lineEndingAuto,
+			Help: "required line ending 'lf' or 'crlf'. 'auto' only rejects mixed line endings and fixes them to the predominant one"},
+		{Name: varWhitespaceIndent, Type: VarTypeString, Help: "required indentation, either 'spaces' or 'tabs'. Indentation is not checked if empty"},
+		{Name: varWhitespaceTabWidth, Type: VarTypeInt, Default: fmt.Sprint(defaultTabWidth), Help: "amount of spaces a tab is converted to and from"},
+	}
+}
+
+func (ws Whitespace) Run(ctx context.Context, in RunInput) Result {
+	if in.Hook != git.HookPreCommit {
+		return hookUnsupported(in.Hook, ws.ID())
+	}
+	rules, err := whitespaceRulesFromVars(in.Vars)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	fix, err := in.Vars.Bool(varWhitespaceFix, false)
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	blobs, contents, err := stagedContents(in, in.Vars.List(varWhitespaceExcludes))
+	if err != nil {
+		return Errorf("%s", err)
+	}
+	includes := in.Vars.List(varWhitespaceIncludes)
+
+	var findings []Finding
+	for i, b := range blobs {
+		if isBinary(contents[i]) || len(includes) > 0 && !matchesAnyGlob(includes, b.Path) {
+			continue
+		}
+		problems := rules.check(b.Path, contents[i])
+		if len(problems) == 0 {
+			continue
+		}
+		if !fix {
+			findings = append(findings, problems...)
+			continue
+		}
+		fix := func(content string) (string, error) { return rules.fix(content), nil }
+		if err = fixStaged(in, b, contents[i], fix); err != nil {
+			return Errorf("could not fix '%s': %+v", b.Path, err)
+		}
+		_, _ = fmt.Fprintf(in.Output, "Fixed %d whitespace problem(s) in '%s'\n", len(problems), b.Path)
+	}
+	if len(findings) > 0 {
+		return Fail(true, fmt.Sprintf("staged files contain whitespace problems. Set '%s' to fix them automatically", varWhitespaceFix), findings...)
+	}
+	return Pass()
+}
+
+func whitespaceRulesFromVars(vars Vars) (whitespaceRules, error) {
+	r := whitespaceRules{checks: whitespaceChecks, lineEnding: vars[varWhitespaceLineEnding], indent: vars[varWhitespaceIndent], tabWidth: defaultTabWidth}
+	if _, ok := vars[varWhitespaceChecks]; ok {
+		r.checks = vars.List(varWhitespaceChecks)
+	}
+	for _, c := range r.checks {
+		if !contains(whitespaceChecks, c) {
+			return r, fmt.Errorf("unknown check '%s'. Supported checks are: %s", c, strings.Join(whitespaceChecks, ", "))
+		}
+	}
+	if r.lineEnding == "" {
+		r.lineEnding = lineEndingAuto
+	}
+	if !contains([]string{lineEndingAuto, lineEndingLF, lineEndingCRLF}, r.lineEnding) {
+		return r, fmt.Errorf("unknown line ending '%s'. Supported are '%s', '%s' and '%s'", r.lineEnding, lineEndingAuto, lineEndingLF, lineEndingCRLF)
+	}
+	if r.indent != "" && r.indent != indentSpaces && r.indent != indentTabs {
+		return r, fmt.Errorf("unknown indentation '%s'. Supported are '%s' and '%s'", r.indent, indentSpaces, indentTabs)
+	}
+	if _, ok := vars[varWhitespaceTabWidth]; ok {
+		w, err := vars.Int(varWhitespaceTabWidth, false)
+		if err != nil {
+			return r, err
+		}
+		if w < 1 {
+			return r, fmt.Errorf("variable '%s' has to be positive", varWhitespaceTabWidth)
+		}
+		r.tabWidth = w
+	}
+	return r, nil
+}
+
+func (r whitespaceRules) enabled(check string) bool {
+	return contains(r.checks, check)
+}
+
+// check returns a finding for every problem within the content
+func (r whitespaceRules) check(file string, content string) []Finding {
+	lines, finalNewline := splitTextLines(content)
+	var findings []Finding
+	report := func(line int, column int, format string, args ...interface{}) {
+		findings = append(findings, Finding{File: file, Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+	}
+	crlf := r.targetCRLF(lines)
+	for n, l := range lines {
+		if r.enabled(checkTrailingWhitespace) {
+			if trimmed := strings.TrimRight(l.text, " \t"); trimmed != l.text {
+				report(n+1, len(trimmed)+1, "trailing whitespace")
+			}
+		}
+		if r.enabled(checkIndentation) && r.indent != "" {
+			if indent := leadingWhitespace(l.text); r.fixIndent(indent) != indent {
+				report(n+1, 1, "indentation has to use %s", r.indent)
+			}
+		}
+		if r.enabled(checkLineEndings) && (n < len(lines)-1 || finalNewline) && l.crlf != crlf {
+			report(n+1, len(l.text)+1, "line ending has to be %s", lineEndingName(crlf))
+		}
+	}
+	if len(lines) == 0 {
+		return findings
+	}
+	if r.enabled(checkFinalNewline) && !finalNewline {
+		report(len(lines), len(lines[len(lines)-1].text)+1, "missing newline at end of file")
+	}
+	if r.enabled(checkBlankLinesEOF) {
+		if blank := trailingBlankLines(lines); blank > 0 && blank < len(lines) {
+			report(len(lines)-blank+1, 1, "%d blank line(s) at end of file", blank)
+		}
+	}
+	return findings
+}
+
+// fix returns the content with all problems of the enabled checks fixed
+func (r whitespaceRules) fix(content string) string {
+	lines, finalNewline := splitTextLines(content)
+	if len(lines) == 0 {
+		return content
+	}
+	crlf := r.targetCRLF(lines)
+	for i := range lines {
+		if r.enabled(checkTrailingWhitespace) {
+			lines[i].text = strings.TrimRight(lines[i].text, " \t")
+		}
+		if r.enabled(checkIndentation) && r.indent != "" {
+			indent := leadingWhitespace(lines[i].text)
+			lines[i].text = r.fixIndent(indent) + lines[i].text[len(indent):]
+		}
+		if r.enabled(checkLineEndings) {
+			lines[i].crlf = crlf
+		}
+	}
+	// whether the last line is terminated by a line ending of its own
+	terminated := finalNewline
+	if r.enabled(checkBlankLinesEOF) {
+		if blank := trailingBlankLines(lines); blank > 0 && blank < len(lines) {
+			lines = lines[:len(lines)-blank]
+			terminated, finalNewline = true, true
+		}
+	}
+	if r.enabled(checkFinalNewline) {
+		finalNewline = true
+	}
+	var b strings.Builder
+	for i, l := range lines {
+		b.WriteString(l.text)
+		switch {
+		case i < len(lines)-1 || terminated:
+			b.WriteString(lineEnding(l.crlf))
+		case finalNewline:
+			b.WriteString(lineEnding(crlf))
+		}
+	}
+	return b.String()
+}
+
+// targetCRLF returns whether lines have to end with CRLF. In auto mode the predominant line ending is used.
+func (r whitespaceRules) targetCRLF(lines []textLine) bool {
+	switch r.lineEnding {
+	case lineEndingCRLF:
+		return true
+	case lineEndingLF:
+		return false
+	}
+	crlf := 0
+	for _, l := range lines {
+		if l.crlf {
+			crlf++
+		}
+	}
+	return crlf > len(lines)-crlf
+}
+
+// fixIndent converts the indentation according to the indentation policy
+func (r whitespaceRules) fixIndent(indent string) string {
+	spaces := strings.Repeat(" ", r.tabWidth)
+	if r.indent == indentSpaces {
+		return strings.ReplaceAll(indent, "\t", spaces)
+	}
+	// spaces which do not fill a whole tab are kept
+	tabs := strings.ReplaceAll(strings.ReplaceAll(indent, "\t", spaces), spaces, "\t")
+	return strings.Repeat("\t", strings.Count(tabs, "\t")) + strings.ReplaceAll(tabs, "\t", "")
+}
+
+// splitTextLines splits the content into lines and returns whether the last line ends with a newline
+func splitTextLines(content string) ([]textLine, bool) {
+	if content == "" {
+		return nil, false
+	}
+	parts := strings.Split(content, "\n")
+	finalNewline := parts[len(parts)-1] == ""
+	if finalNewline {
+		parts = parts[:len(parts)-1]
+	}
+	lines := make([]textLine, len(parts))
+	for i, p := range parts {
+		// the last line does not have a line ending in case the final newline is missing
+		crlf := strings.HasSuffix(p, "\r") && (i < len(parts)-1 || finalNewline)
+		if crlf {
+			p = strings.TrimSuffix(p, "\r")
+		}
+		lines[i] = textLine{text: p, crlf: crlf}
+	}
+	return lines, finalNewline
+}
+
+// trailingBlankLines returns the amount of blank lines at the end
+func trailingBlankLines(lines []textLine) int {
+	blank := 0
+	for i := len(lines) - 1; i >= 0 && strings.TrimSpace(lines[i].text) == ""; i-- {
+		blank++
+	}
+	return blank
+}
+
+func leadingWhitespace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+func lineEnding(crlf bool) string {
+	if crlf {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+func lineEndingName(crlf bool) string {
+	if crlf {
+		return "CRLF"
+	}
+	return "LF"
+}
@@ -0,0 +1,95 @@
+package plugins
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWhitespaceRules(t *testing.T) {
+	ruleTests := []struct {
+		name              string
+		vars              map[string]string
+		content           string
+		locationsExpected []string
+		fixedExpected     string
+	}{
+		{"clean", map[string]string{}, "a\nb\n", nil, "a\nb\n"},
+		{"trailing whitespace", map[string]string{}, "a \nb\t\n", []string{"f:1:2", "f:2:2"}, "a\nb\n"},
+		{"missing final newline", map[string]string{}, "a\nb", []string{"f:2:2"}, "a\nb\n"},
+		{"blank lines at eof", map[string]string{}, "a\n\n \n", []string{"f:3:1", "f:2:1"}, "a\n"},
+		{"mixed line endings", map[string]string{}, "a\r\nb\r\nc\n", []string{"f:3:2"}, "a\r\nb\r\nc\r\n"},
+		{"required lf", map[string]string{"WHITESPACE_LINE_ENDING": "lf"}, "a\r\nb", []string{"f:1:2", "f:2:2"}, "a\nb\n"},
+		{"indent with spaces", map[string]string{"WHITESPACE_INDENT": "spaces", "WHITESPACE_TAB_WIDTH": "2"}, "\ta\n  b\n", []string{"f:1:1"}, "  a\n  b\n"},
+		{"indent with tabs", map[string]string{"WHITESPACE_INDENT": "tabs"}, "      a\n\tb\n", []string{"f:1:1"}, "\t  a\n\tb\n"},
+		{"disabled checks", map[string]string{"WHITESPACE_CHECKS": "final-newline"}, "a  \r\nb\n\n", nil, "a  \r\nb\n\n"},
+	}
+	for _, tt := range ruleTests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := whitespaceRulesFromVars(tt.vars)
+			assert.NoError(t, err, "rules should be valid")
+			var locations []string
+			for _, f := range rules.check("f", tt.content) {
+				locations = append(locations, strings.SplitN(f.String(), ": ", 2)[0])
+			}
+			assert.Equal(t, tt.locationsExpected, locations, "expected problems should be reported")
+			assert.Equal(t, tt.fixedExpected, rules.fix(tt.content), "content should be fixed")
+		})
+	}
+	_, err := whitespaceRulesFromVars(map[string]string{"WHITESPACE_CHECKS": "tabs"})
+	assert.Error(t, err, "unknown checks should be rejected")
+}
+
+func TestWhitespaceFix(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("a.txt", "one  \ntwo\n")
+	tr.WriteFile("b.md", "keep  \n")
+	tr.WriteFile("c.bin", "\x00binary  \n")
+	tr.AddAll()
+	// unstaged change within the working tree which must not be staged
+	tr.WriteFile("a.txt", "one  \ntwo\nthree  \n")
+
+	in := testInput(git.HookPreCommit, map[string]string{"WHITESPACE_EXCLUDES": "*.md"}, nil)
+	in.Repo = git.NewRepository(tr.AbsDir())
+	ws, _ := Get("whitespace")
+	res := ws.Run(context.Background(), in)
+	assert.Equal(t, StatusFail, res.Status, "problems should fail the hook: %s", res)
+	assert.Len(t, res.Findings, 1, "only the staged text file should be reported")
+
+	in.Vars = map[string]string{"WHITESPACE_EXCLUDES": "*.md", "WHITESPACE_FIX": "true"}
+	res = ws.Run(context.Background(), in)
+	assert.Equal(t, StatusPass, res.Status, "problems should be fixed: %s", res)
+
+	staged, _ := tr.Command("show", ":a.txt")
+	assert.Equal(t, "one\ntwo\n", staged, "staged content should be fixed without staging unstaged changes")
+	worktree, _ := os.ReadFile(filepath.Join(tr.AbsDir(), "a.txt"))
+	assert.Equal(t, "one\ntwo\nthree\n", string(worktree), "working tree should be fixed as well")
+	excluded, _ := tr.Command("show", ":b.md")
+	assert.Equal(t, "keep  \n", excluded, "excluded files should not be fixed")
+}
+
+func TestWhitespace_shouldIgnoreSymlinks(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	tr.WriteFile("real.txt", "real  ")
+	assert.NoError(t, os.Symlink("real.txt", filepath.Join(tr.AbsDir(), "link")), "symlink should be created")
+	_, _ = tr.Command("add", "link")
+
+	in := testInput(git.HookPreCommit, map[string]string{}, nil)
+	in.Repo = git.NewRepository(tr.AbsDir())
+	ws, _ := Get("whitespace")
+	res := ws.Run(context.Background(), in)
+	assert.Equal(t, StatusPass, res.Status, "link targets should not be checked: %s", res)
+
+	in.Vars = map[string]string{"WHITESPACE_FIX": "true"}
+	res = ws.Run(context.Background(), in)
+	assert.Equal(t, StatusPass, res.Status, "link targets should not be fixed: %s", res)
+	staged, _ := tr.Command("show", ":link")
+	assert.Equal(t, "real.txt", staged, "staged link target should be untouched")
+	target, _ := os.ReadFile(filepath.Join(tr.AbsDir(), "real.txt"))
+	assert.Equal(t, "real  ", string(target), "file the link points to should be untouched")
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/jenpet/giks/config"
 	"github.com/jenpet/giks/log"
 	"github.com/jenpet/giks/meta"
+	"strings"
 )
 
 var showCommand = flag.NewFlagSet("show", flag.ExitOnError)
@@ -31,20 +32,39 @@ func Process(cfg config.Config, gargs gargs.GiksArgs) {
 			break
 		}
 		uninstallHookList(cfg)
+	case "sync":
+		verifyUserConfirmation(fmt.Sprintf("Do you want to sync the configured hooks for git directory '%s'", cfg.GitDir))
+		stale, err := syncHooks(cfg)
+		if err != nil {
+			log.Errorf("failed syncing hooks. Error: %s", err)
+		}
+		if len(stale) > 0 {
+			log.Infof("Replaced or removed stale hook(s) '%s'", strings.Join(stale, ", "))
+		}
 	case "exec":
 		if err := executeHook(cfg, gargs); err != nil {
 			log.Errorf("failed executing '%s' hook. Error: %s", gargs.Hook(), err)
 		}
+	case "check-messages":
+		if err := checkCommitMessageRange(cfg, gargs); err != nil {
+			log.Errorf("failed checking commit messages. Error: %s", err)
+		}
 	case "show":
 		if gargs.HasHook() {
-			cli.PrintTemplate(detailsTemplate, cfg.Hook(gargs.Hook()).ToMap())
+			h := cfg.Hook(gargs.Hook())
+			cli.PrintTemplate(detailsTemplate, h.ToMap())
+			reportPluginSteps(cfg, map[string]config.Hook{h.Name: h})
 			break
 		}
 		_ = showCommand.Parse(args)
 		if showCommand.Parsed() {
 			all := *showAllAttr
-			cli.PrintTemplate(listTemplate, cfg.HookList(all))
+			hooks := cfg.HookList(all)
+			cli.PrintTemplate(listTemplate, hooks)
+			reportPluginSteps(cfg, hooks)
 		}
+	case "plugins":
+		processPlugins(cfg, gargs.Args(false))
 	case "help":
 		printHelp(cfg, gargs)
 	case "version":
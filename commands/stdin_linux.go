@@ -0,0 +1,12 @@
+package commands
+
+import (
+	"os"
+	"syscall"
+)
+
+// redirectStdin duplicates the file onto the file descriptor of stdin. Linux does not provide dup2 on all
+// architectures, hence dup3 is used.
+func redirectStdin(f *os.File) error {
+	return syscall.Dup3(int(f.Fd()), int(os.Stdin.Fd()), 0)
+}
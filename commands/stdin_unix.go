@@ -0,0 +1,14 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package commands
+
+import (
+	"os"
+	"syscall"
+)
+
+// redirectStdin duplicates the file onto the file descriptor of stdin
+func redirectStdin(f *os.File) error {
+	return syscall.Dup2(int(f.Fd()), int(os.Stdin.Fd()))
+}
@@ -0,0 +1,11 @@
+package commands
+
+import (
+	"errors"
+	"os"
+)
+
+// redirectStdin is not supported since processes can not be replaced on Windows
+func redirectStdin(f *os.File) error {
+	return errors.New("replacing stdin is not supported on windows")
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"github.com/jenpet/giks/args"
 	"github.com/jenpet/giks/log"
 	"os"
@@ -16,83 +17,108 @@ import (
 // default filename for giks configs in case none is provided on invocation
 const defaultGiksConfigFilename = "giks.yml"
 
+// ErrConfigNotFound is returned in case the used configuration file does not exist
+var ErrConfigNotFound = errors.New("config file not found")
+
 // AssembleConfig takes giks specific arguments and parses the configuration file for giks in order to return a config.
 // Additionally, it sanitizes the given inputs targeting files and returns a configuration which can be
 // used without bothering about paths.
 func AssembleConfig(ga args.GiksArgs) Config {
-	cfg := parseConfigFile(ga.ConfigFile())
-	cfg.GitDir = absoluteGitDirectory(ga.GitDir())
-	cfg.WorkingDir = path.Dir(cfg.GitDir)
-	cfg.Binary = absoluteBinaryPath(ga.Binary())
+	cfg, err := assembleConfig(ga.ConfigFile(), ga.GitDir(), ga.Binary())
+	if err != nil {
+		log.Error(err)
+	}
 	return cfg
 }
 
+// AssembleRepositoryConfig assembles the configuration for the repository located in dir using the default
+// configuration file within the repository. Contrary to AssembleConfig failures do not cause giks to exit but are
+// returned. A missing configuration file results in an error wrapping ErrConfigNotFound.
+func AssembleRepositoryConfig(ga args.GiksArgs, dir string) (Config, error) {
+	return assembleConfig(filepath.Join(dir, defaultGiksConfigFilename), dir, ga.Binary())
+}
+
+func assembleConfig(file string, gitDir string, binary string) (Config, error) {
+	cfg, err := readConfigFile(file)
+	if err != nil {
+		return Config{}, err
+	}
+	if cfg.GitDir, err = absoluteGitDirectory(gitDir); err != nil {
+		return Config{}, err
+	}
+	cfg.WorkingDir = workingDirectory(cfg.GitDir)
+	if cfg.Binary, err = absoluteBinaryPath(binary); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
 // absoluteBinaryPath determines the absolute path of the binary provided as a string.
 // Three different scenarios are addressed:
 // - binary string is already provided in an absolute way
 // - binary string is provided relatively to the cwd
 // - binary string is no file at all and presumably in the $PATH of the machine
-func absoluteBinaryPath(binary string) string {
+func absoluteBinaryPath(binary string) (string, error) {
 	if binary == "" {
-		log.Error("Could not determine absolute path of binary. Provided binary name or filepath was empty.")
+		return "", errors.New("could not determine absolute path of binary. Provided binary name or filepath was empty")
 	}
 	// check if the used binary file exists and might be relative or absolute
 	if fi, _ := os.Stat(binary); fi != nil {
 		// binary is already an absolute path
 		if filepath.IsAbs(binary) {
-			return binary
+			return binary, nil
 		}
 		// get the absolute path to the binary
 		path, err := filepath.Abs(binary)
 		if err != nil {
-			log.Errorf("Could not get absolute path to '%s' binary. Error: %+v", binary, err)
+			return "", fmt.Errorf("could not get absolute path to '%s' binary. Error: %+v", binary, err)
 		}
-		return path
+		return path, nil
 	}
 
 	// binary is presumably in the $PATH env var
 	path, err := exec.LookPath(binary)
 	if err != nil {
-		log.Errorf("Could not get absolute path to '%s' binary. Error: %+v", binary, err)
+		return "", fmt.Errorf("could not get absolute path to '%s' binary. Error: %+v", binary, err)
 	}
-	return path
+	return path, nil
 }
 
 // absoluteConfigFile determines the absolute path of the provided configuration file.
 // In case no file was provided it will assume that the default configuration file is used in the cwd.
-// Absence of the configuration file will cause giks to exit.
-func absoluteConfigFile(file string) string {
+// Absence of the configuration file results in an error wrapping ErrConfigNotFound.
+func absoluteConfigFile(file string) (string, error) {
 	// validate the given input file
 	if file != "" {
 		file = absoluteFilepath(file)
 		if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
-			log.Errorf("The provided config file '%s' does not exist", file)
+			return "", fmt.Errorf("the provided config file '%s' does not exist: %w", file, ErrConfigNotFound)
 		}
-		return file
+		return file, nil
 	}
 
 	// use the default by utilizing the cwd
 	path, err := os.Getwd()
 	if err != nil {
-		log.Error("Failed retrieving cwd. No config file provided. Fallback with default config not possible.")
+		return "", errors.New("failed retrieving cwd. No config file provided. Fallback with default config not possible")
 	}
 	file = absoluteFilepath(filepath.Join(path, defaultGiksConfigFilename))
 	if _, err = os.Stat(file); errors.Is(err, os.ErrNotExist) {
-		log.Errorf("No valid config file provided. Tried to read the default configuration '%s' but it does not exist.", file)
+		return "", fmt.Errorf("no valid config file provided. Tried to read the default configuration '%s' but it does not exist: %w", file, ErrConfigNotFound)
 	}
-	return file
+	return file, nil
 }
 
 // absoluteGitDirectory looks up the responsible git directory originating from a given directory. The absence of a
 // directory results in a fallback to the absolute path to the cwd.
 // Attention: the git command has to be present in the $PATH variable in order to identify the git directory.
-func absoluteGitDirectory(dir string) string {
+func absoluteGitDirectory(dir string) (string, error) {
 	if dir != "" {
 		dir = absoluteFilepath(dir)
 	} else {
 		path, err := os.Getwd()
 		if err != nil {
-			log.Error("Failed retrieving cwd. No config file provided. Fallback with default config not possible.")
+			return "", errors.New("failed retrieving cwd. No git directory provided. Fallback with cwd not possible")
 		}
 		dir = absoluteFilepath(path)
 	}
@@ -103,7 +129,7 @@ func absoluteGitDirectory(dir string) string {
 	var buf bytes.Buffer
 	cmd.Stdout = &buf
 	if err := cmd.Run(); err != nil {
-		log.Errorf("Failed validating git directory '%s'. Error: %+v", dir, err)
+		return "", fmt.Errorf("failed validating git directory '%s'. Error: %+v", dir, err)
 	}
 
 	// if the output of the git command is not an absolute directory it is a child of the given dir
@@ -113,7 +139,17 @@ func absoluteGitDirectory(dir string) string {
 	} else {
 		dir = gitDir
 	}
-	return dir
+	return dir, nil
+}
+
+// workingDirectory returns the root of the working tree belonging to the git directory. Bare repositories do not
+// have a working tree, hence the git directory itself is used.
+func workingDirectory(gitDir string) string {
+	out, err := exec.Command("git", "--git-dir", gitDir, "rev-parse", "--is-bare-repository").Output()
+	if err == nil && strings.TrimSpace(string(out)) == "true" {
+		return gitDir
+	}
+	return path.Dir(gitDir)
 }
 
 // absoluteFilepath returns the absolute path to a given file. Since '~' does not get resolved by the golang standard
@@ -25,6 +25,19 @@ func TestAssembleConfig_shouldParseConfigAndValidatePaths(t *testing.T) {
 	assert.Equal(t, r.AbsGitDir(), cfg.GitDir, "git directory has to be available via an absolute path")
 }
 
+func TestWorkingDirectory_shouldUseGitDirectoryForBareRepositories(t *testing.T) {
+	r := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	bare := filepath.Join(t.TempDir(), "server.git")
+	assert.NoError(t, exec.Command("git", "init", "-q", "--bare", bare).Run(), "bare repository should be initialized")
+
+	cfg := Config{GitDir: r.AbsGitDir(), WorkingDir: workingDirectory(r.AbsGitDir())}
+	assert.Equal(t, r.AbsDir(), cfg.WorkingDir, "working directory should be the root of the working tree")
+	assert.False(t, cfg.Bare(), "repository with a working tree should not be bare")
+	cfg = Config{GitDir: bare, WorkingDir: workingDirectory(bare)}
+	assert.Equal(t, bare, cfg.WorkingDir, "working directory of a bare repository should be the git directory")
+	assert.True(t, cfg.Bare(), "repository should be bare")
+}
+
 func TestAbsoluteFilePath_shouldResolveCorrectly(t *testing.T) {
 	pathTests := []struct {
 		name        string
@@ -5,12 +5,38 @@ import (
 	"fmt"
 	"github.com/jenpet/giks/git"
 	"github.com/jenpet/giks/log"
+	"path/filepath"
 	"strings"
 )
 
 // minimumConfigVersion which the giks binary requires
 const minimumConfigVersion = 1.0
 
+const (
+	// InstallModeAbsolute writes hooks which call giks via the absolute binary and config paths
+	InstallModeAbsolute = "absolute"
+	// InstallModePortable writes hooks as a POSIX shell shim which looks up giks at runtime and
+	// refers to the configuration relative to the repository root
+	InstallModePortable = "portable"
+
+	// MissingBinarySkip lets a portable hook succeed in case giks could not be found
+	MissingBinarySkip = "skip"
+	// MissingBinaryFail lets a portable hook fail in case giks could not be found
+	MissingBinaryFail = "fail"
+)
+
+const (
+	// BlockFixup blocks commits created via 'git commit --fixup', i.e. with a subject starting with 'fixup!' or 'amend!'
+	BlockFixup = "fixup"
+	// BlockSquash blocks commits created via 'git commit --squash', i.e. with a subject starting with 'squash!'
+	BlockSquash = "squash"
+	// BlockWIP blocks commits with a subject starting with 'WIP'
+	BlockWIP = "wip"
+)
+
+// BlockableCommits are the kinds of commits which can be blocked when checking commit messages
+var BlockableCommits = []string{BlockFixup, BlockSquash, BlockWIP}
+
 // Config holds the config information provided by the used configuration file and additional
 // meta information which is available at runtime.
 type Config struct {
@@ -18,12 +44,17 @@ type Config struct {
 	ConfigFile string `yaml:"-"`
 	// absolute path to the affected git repository
 	GitDir string `yaml:"-"`
-	// working directory for hook executions which defaults to the root of the repository
+	// working directory for hook executions which defaults to the root of the repository or the git directory for
+	// bare repositories
 	WorkingDir string `yaml:"-"`
 	// absolute path to the giks binary file
 	Binary string `yaml:"-"`
 	// parsed hook configurations based on the configuration file
 	Hooks map[string]Hook `yaml:"hooks"`
+	// settings which influence how hooks are installed into the git directory
+	Installation Installation `yaml:"installation"`
+	// settings for plugins which are not built into giks
+	Plugins Plugins `yaml:"plugins"`
 	// version of the configuration in case backwards compatibility is not an option at some point
 	Version float32 `yaml:"version"`
 }
@@ -76,16 +107,73 @@ func (c Config) validate() error {
 			return fmt.Errorf("hook '%s' is invalid: %s", name, err)
 		}
 	}
+	if err := c.Installation.validate(); err != nil {
+		return fmt.Errorf("installation is invalid: %s", err)
+	}
 	if c.Version < minimumConfigVersion {
 		return fmt.Errorf("configuration is missing version or is not supported. Minimum required version is '%g'", minimumConfigVersion)
 	}
 	return nil
 }
 
+// Installation holds the settings for writing hook files. Omitted values fall back to an absolute installation
+// which skips hook executions in case giks can not be found.
+type Installation struct {
+	// Mode is either 'absolute' or 'portable'
+	Mode string `yaml:"mode"`
+	// OnMissingBinary is either 'skip' or 'fail' and is only taken into account by portable hooks
+	OnMissingBinary string `yaml:"on_missing_binary"`
+}
+
+// Portable returns whether hooks should be installed as a portable shell shim
+func (i Installation) Portable() bool {
+	return i.Mode == InstallModePortable
+}
+
+// FailOnMissingBinary returns whether a portable hook should fail in case the giks binary can not be found
+func (i Installation) FailOnMissingBinary() bool {
+	return i.OnMissingBinary == MissingBinaryFail
+}
+
+func (i Installation) validate() error {
+	switch i.Mode {
+	case "", InstallModeAbsolute, InstallModePortable:
+	default:
+		return fmt.Errorf("unknown mode '%s'. Supported modes are '%s' and '%s'", i.Mode, InstallModeAbsolute, InstallModePortable)
+	}
+	switch i.OnMissingBinary {
+	case "", MissingBinarySkip, MissingBinaryFail:
+	default:
+		return fmt.Errorf("unknown missing binary policy '%s'. Supported policies are '%s' and '%s'", i.OnMissingBinary, MissingBinarySkip, MissingBinaryFail)
+	}
+	return nil
+}
+
+// Plugins holds the settings for plugins which are not built into giks
+type Plugins struct {
+	// Directory containing external plugin executables. Relative paths are resolved from the repository root.
+	Directory string `yaml:"directory"`
+}
+
+// Bare returns whether the repository is a bare one without a working tree, e.g. on a server
+func (c Config) Bare() bool {
+	return c.WorkingDir != "" && c.WorkingDir == c.GitDir
+}
+
+// PluginDirectory returns the absolute path of the configured plugin directory or an empty string if none is set
+func (c Config) PluginDirectory() string {
+	if c.Plugins.Directory == "" || filepath.IsAbs(c.Plugins.Directory) {
+		return c.Plugins.Directory
+	}
+	return filepath.Join(c.WorkingDir, c.Plugins.Directory)
+}
+
 type Hook struct {
 	Enabled bool   `yaml:"enabled"`
 	Steps   []Step `yaml:"steps"`
-	Name    string `yaml:"-"`
+	// CommitMessages applies the message validating steps of another hook to every pushed commit
+	CommitMessages CommitMessages `yaml:"commit_messages"`
+	Name           string         `yaml:"-"`
 }
 
 func (h Hook) validate() error {
@@ -98,6 +186,9 @@ func (h Hook) validate() error {
 	if !valid {
 		return fmt.Errorf("hook '%s' is not a valid Git hook", h.Name)
 	}
+	if err := h.CommitMessages.validate(h.Name); err != nil {
+		return fmt.Errorf("commit messages are invalid: %s", err)
+	}
 	return nil
 }
 
@@ -113,6 +204,50 @@ func (h Hook) ToMap() map[string]interface{} {
 	return m
 }
 
+// CommitMessages holds the settings for checking the messages of all commits received by a hook
+type CommitMessages struct {
+	Enabled bool `yaml:"enabled"`
+	// Hook whose message validating steps are applied to every commit, defaults to 'commit-msg'
+	Hook string `yaml:"hook"`
+	// Block lists the kinds of commits which are rejected regardless of the steps, see BlockableCommits
+	Block []string `yaml:"block"`
+}
+
+// MessageHook returns the hook whose steps validate the commit messages
+func (cm CommitMessages) MessageHook() string {
+	if cm.Hook == "" {
+		return git.HookCommitMsg
+	}
+	return cm.Hook
+}
+
+func (cm CommitMessages) validate(hook string) error {
+	if !cm.Enabled {
+		return nil
+	}
+	if hook != git.HookPrePush && hook != git.HookPreReceive {
+		return fmt.Errorf("only supported by the hooks '%s' and '%s'", git.HookPrePush, git.HookPreReceive)
+	}
+	if !git.IsValidHook(cm.MessageHook()) {
+		return fmt.Errorf("hook '%s' is not a valid Git hook", cm.MessageHook())
+	}
+	return ValidateBlockedCommits(cm.Block)
+}
+
+// ValidateBlockedCommits returns an error in case one of the kinds of commits can not be blocked, see BlockableCommits
+func ValidateBlockedCommits(kinds []string) error {
+OUTER:
+	for _, k := range kinds {
+		for _, b := range BlockableCommits {
+			if k == b {
+				continue OUTER
+			}
+		}
+		return fmt.Errorf("unknown kind of commit '%s'. Supported kinds are '%s'", k, strings.Join(BlockableCommits, "', '"))
+	}
+	return nil
+}
+
 type Step struct {
 	Command string     `yaml:"command"`
 	Exec    string     `yaml:"exec"`
@@ -30,17 +30,29 @@ func parseConfig(r io.Reader) (*Config, error) {
 }
 
 func parseConfigFile(file string) Config {
-	absFile := absoluteConfigFile(file)
+	cfg, err := readConfigFile(file)
+	if err != nil {
+		log.Error(err)
+	}
+	return cfg
+}
+
+func readConfigFile(file string) (Config, error) {
+	absFile, err := absoluteConfigFile(file)
+	if err != nil {
+		return Config{}, err
+	}
 	fh, err := os.Open(absFile)
 	if err != nil {
-		log.Errorf("Failed accessing configuration file. Error: %s", err)
+		return Config{}, fmt.Errorf("failed accessing configuration file. Error: %s", err)
 	}
+	defer fh.Close()
 	cfg, err := parseConfig(fh)
 	if err != nil {
-		log.Errorf("Failed parsing provided configuration. Error: %s", err)
+		return Config{}, fmt.Errorf("failed parsing provided configuration. Error: %s", err)
 	}
 	cfg.ConfigFile = absFile
-	return *cfg
+	return *cfg, nil
 }
 
 func parseError(reason string) error {
@@ -17,7 +17,7 @@ func TestParseConfigFile_whenInputIsValid_shouldParseCorrectly(t *testing.T) {
 	assert.Len(t, cfg.HookList(true), 3, "hook list should be filtered for active hooks")
 
 	// test Hook() and LookupHook()
-	assert.Equal(t, Hook{false, nil, "pre-push"}, cfg.Hook("pre-push"), "hook from the config should be returned")
+	assert.Equal(t, Hook{Enabled: false, Name: "pre-push"}, cfg.Hook("pre-push"), "hook from the config should be returned")
 	lookup, err := cfg.LookupHook("absent")
 	assert.Nil(t, lookup, "no hook result expected when looking up an absent hook")
 	assert.Error(t, err, "error expected when looking up an absent hook")
@@ -50,6 +50,27 @@ hooks:
   foo:
     enabled: true`),
 		},
+		{
+			"commit messages on unsupported hook",
+			strings.NewReader(`
+version: 1
+hooks:
+  pre-commit:
+    enabled: true
+    commit_messages:
+      enabled: true`),
+		},
+		{
+			"unknown blocked commits",
+			strings.NewReader(`
+version: 1
+hooks:
+  pre-push:
+    enabled: true
+    commit_messages:
+      enabled: true
+      block: ['fixup', 'draft']`),
+		},
 	}
 
 	for _, tt := range configTests {
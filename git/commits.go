@@ -0,0 +1,58 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Commit holds the metadata of a commit
+type Commit struct {
+	SHA         string
+	Parents     []string
+	AuthorName  string
+	AuthorEmail string
+	// Message is the raw commit message including its trailers
+	Message string
+}
+
+// ShortSHA abbreviates the object name of the commit for messages
+func (c Commit) ShortSHA() string {
+	if len(c.SHA) > 7 {
+		return c.SHA[:7]
+	}
+	return c.SHA
+}
+
+// Subject returns the first line of the commit message
+func (c Commit) Subject() string {
+	return strings.SplitN(strings.TrimSpace(c.Message), "\n", 2)[0]
+}
+
+// commitFormat separates the fields of a commit by NUL bytes since they can not be part of any field
+const commitFormat = "%H%x00%P%x00%an%x00%ae%x00%B"
+
+// commitFields is the amount of fields written by the commit format
+const commitFields = 5
+
+// Commits returns the metadata of the commits in the given order. The commits are passed via stdin since pushes may
+// contain more commits than the command line can hold.
+func (r Repository) Commits(shas ...string) ([]Commit, error) {
+	if len(shas) == 0 {
+		return nil, nil
+	}
+	out, err := r.CommandInput(strings.Join(shas, "\n")+"\n", "log", "--no-walk=unsorted", "--stdin", "-z", "--format="+commitFormat)
+	if err != nil {
+		return nil, err
+	}
+	// commits are separated by a NUL byte as well
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	if len(fields) != len(shas)*commitFields {
+		return nil, fmt.Errorf("expected %d commits from log but got %d fields", len(shas), len(fields))
+	}
+	commits := make([]Commit, len(shas))
+	for i := range commits {
+		f := fields[i*commitFields : (i+1)*commitFields]
+		commits[i] = Commit{SHA: f[0], Parents: strings.Fields(f[1]), AuthorName: f[2], AuthorEmail: f[3], Message: f[4]}
+	}
+	return commits, nil
+}
@@ -0,0 +1,40 @@
+package git
+
+import (
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepositoryCommits(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	r := NewRepository(tr.AbsDir())
+	tr.WriteFile("a.txt", "a")
+	tr.AddAll()
+	tr.Commit("first\n\nbody")
+	first, _ := r.Command("rev-parse", "HEAD")
+	tr.WriteFile("a.txt", "b")
+	tr.AddAll()
+	tr.Commit("second")
+	second, _ := r.Command("rev-parse", "HEAD")
+
+	commits, err := r.Commits(second, first)
+	assert.NoError(t, err, "commits should be determined")
+	if assert.Len(t, commits, 2, "all commits should be returned") {
+		assert.Equal(t, second, commits[0].SHA, "commits should be returned in the given order")
+		assert.Equal(t, []string{first}, commits[0].Parents, "parents should be determined")
+		assert.Empty(t, commits[1].Parents, "root commit should not have parents")
+		assert.Equal(t, "first\n\nbody\n", commits[1].Message, "raw message should be returned")
+		assert.NotEmpty(t, commits[1].AuthorEmail, "author should be determined")
+	}
+	_, err = r.Commits("0123456789012345678901234567890123456789")
+	assert.Error(t, err, "missing commits should result in an error")
+}
+
+func TestCommit_shortSHAAndSubject(t *testing.T) {
+	c := Commit{SHA: "0123456789abcdef0123456789abcdef01234567", Message: "\nfix: subject\n\nbody\n"}
+	assert.Equal(t, "0123456", c.ShortSHA(), "object name should be abbreviated")
+	assert.Equal(t, "fix: subject", c.Subject(), "first line of the message should be the subject")
+	assert.Equal(t, "abc", Commit{SHA: "abc"}.ShortSHA(), "short object names should be kept")
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AddedLine is a line which was added by a change
+type AddedLine struct {
+	// File is the path of the file relative to the root of the repository
+	File string
+	// Line is the 1-based line number within the new version of the file
+	Line int
+	Text string
+}
+
+// diffPrefixes are passed explicitly since the configured prefixes (e.g. 'diff.noprefix') would break the parsing of
+// the paths
+var diffPrefixes = []string{"--src-prefix=a/", "--dst-prefix=b/"}
+
+// StagedAddedLines returns all lines added by the staged changes
+func (r Repository) StagedAddedLines() ([]AddedLine, error) {
+	args := append([]string{"-c", "core.quotepath=off", "diff", "--cached", "-U0", "--no-color", "--no-ext-diff", "--no-renames"}, diffPrefixes...)
+	out, err := r.CommandInput("", args...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAddedLines(out), nil
+}
+
+// CommitAddedLines returns all lines added by the commit compared to its first parent. Merge commits do not add any
+// lines on their own.
+func (r Repository) CommitAddedLines(sha string) ([]AddedLine, error) {
+	args := append([]string{"-c", "core.quotepath=off", "diff-tree", "-p", "-U0", "--no-color", "--no-ext-diff", "--no-renames", "--root", "--no-commit-id"}, diffPrefixes...)
+	out, err := r.CommandInput("", append(args, sha)...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAddedLines(out), nil
+}
+
+// ParseAddedLines extracts the added lines of a unified diff
+func ParseAddedLines(diff string) []AddedLine {
+	var lines []AddedLine
+	file := ""
+	line := 0
+	inHunk := false
+	for _, l := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(l, "diff "):
+			file, inHunk = "", false
+		case !inHunk && strings.HasPrefix(l, "+++ "):
+			file = diffPath(strings.TrimPrefix(l, "+++ "))
+		case strings.HasPrefix(l, "@@ "):
+			line, inHunk = hunkStart(l), true
+		case !inHunk || file == "":
+		case strings.HasPrefix(l, "+"):
+			lines = append(lines, AddedLine{File: file, Line: line, Text: strings.TrimPrefix(l, "+")})
+			line++
+		case strings.HasPrefix(l, " "):
+			line++
+		}
+	}
+	return lines
+}
+
+// diffPath returns the path of a '+++' header line without its prefix. Deleted files result in an empty path.
+func diffPath(p string) string {
+	if strings.HasPrefix(p, `"`) {
+		if unquoted, err := strconv.Unquote(p); err == nil {
+			p = unquoted
+		}
+	}
+	if p == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(p, "b/")
+}
+
+// hunkStart returns the first line number of the new file within a hunk header like '@@ -1,2 +3,4 @@'
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0
+	}
+	start := strings.SplitN(strings.TrimPrefix(fields[2], "+"), ",", 2)[0]
+	n, _ := strconv.Atoi(start)
+	return n
+}
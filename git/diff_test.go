@@ -0,0 +1,68 @@
+package git
+
+import (
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAddedLines(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,0 +4,2 @@ package main
++++counter
++var token = "x"
+@@ -10 +12 @@ func main() {
+-	old()
++	new()
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+diff --git "a/sp\303\244ce.txt" "b/sp\303\244ce.txt"
+--- "a/sp\303\244ce.txt"
++++ "b/sp\303\244ce.txt"
+@@ -0,0 +1 @@
++hello`
+	expected := []AddedLine{
+		{File: "main.go", Line: 4, Text: "++counter"},
+		{File: "main.go", Line: 5, Text: `var token = "x"`},
+		{File: "main.go", Line: 12, Text: "\tnew()"},
+		{File: "späce.txt", Line: 1, Text: "hello"},
+	}
+	assert.Equal(t, expected, ParseAddedLines(diff), "added lines should be parsed from the diff")
+}
+
+func TestRepositoryAddedLines(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	r := NewRepository(tr.AbsDir())
+	tr.WriteFile("a.txt", "one\ntwo\n")
+	tr.AddAll()
+	tr.Commit("initial")
+	tr.WriteFile("a.txt", "one\nthree\ntwo\nfour \n")
+	tr.AddAll()
+	// configured prefixes must not affect the parsed paths
+	_, _ = tr.Command("config", "diff.noprefix", "true")
+
+	staged, err := r.StagedAddedLines()
+	assert.NoError(t, err, "staged lines should be determined")
+	assert.Equal(t, []AddedLine{{File: "a.txt", Line: 2, Text: "three"}, {File: "a.txt", Line: 4, Text: "four "}}, staged,
+		"only the staged lines should be added")
+
+	_, _ = tr.Command("config", "--unset", "diff.noprefix")
+	_, _ = tr.Command("config", "diff.mnemonicPrefix", "true")
+	staged, err = r.StagedAddedLines()
+	assert.NoError(t, err, "staged lines should be determined")
+	assert.Equal(t, []AddedLine{{File: "a.txt", Line: 2, Text: "three"}, {File: "a.txt", Line: 4, Text: "four "}}, staged,
+		"mnemonic prefixes must not be part of the paths")
+
+	root, err := r.CommitAddedLines("HEAD")
+	assert.NoError(t, err, "lines of the root commit should be determined")
+	assert.Equal(t, []AddedLine{{File: "a.txt", Line: 1, Text: "one"}, {File: "a.txt", Line: 2, Text: "two"}}, root,
+		"root commit should add all lines")
+}
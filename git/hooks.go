@@ -5,6 +5,8 @@ const (
 	HookApplyPatchMsg     = "applypatch-msg"
 	HookCommitMsg         = "commit-msg"
 	HookFsMonitorWatchman = "fsmonitor-watchman"
+	HookPostReceive       = "post-receive"
+	HookPostRewrite       = "post-rewrite"
 	HookPostUpdate        = "post-update"
 	HookPreApplyPatch     = "pre-applypatch"
 	HookPreCommit         = "pre-commit"
@@ -21,6 +23,8 @@ var Hooks = []string{
 	HookApplyPatchMsg,
 	HookCommitMsg,
 	HookFsMonitorWatchman,
+	HookPostReceive,
+	HookPostRewrite,
 	HookPostUpdate,
 	HookPreApplyPatch,
 	HookPreCommit,
@@ -76,3 +76,17 @@ func execGitCommand(dir string, arg ...string) (string, error) {
 	}
 	return strings.TrimSpace(buf.String()), nil
 }
+
+// execGitCommandInput executes a git command which reads the given input from stdin. Other than execGitCommand the
+// output is not trimmed and does not contain stderr since it is usually parsed line by line.
+func execGitCommandInput(dir string, input string, arg ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, arg...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed executing git command '%s'. Error: %s", strings.Join(arg, " "), stderr.String())
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,188 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// submoduleMode is the file mode of gitlinks which do not point to a blob
+	submoduleMode = "160000"
+	// regularFileMode and executableFileMode are the file modes of blobs holding the content of a file
+	regularFileMode    = "100644"
+	executableFileMode = "100755"
+)
+
+// ChangedBlob is a blob which was added or modified by a change
+type ChangedBlob struct {
+	// Path of the file relative to the root of the repository
+	Path string
+	SHA  string
+	Mode string
+	// Status is the type of the change, e.g. 'A' for added or 'M' for modified files
+	Status string
+}
+
+// Regular returns whether the blob holds the content of a regular file. Symlinks, whose blob holds the link target,
+// and gitlinks are not regular.
+func (b ChangedBlob) Regular() bool {
+	return b.Mode == regularFileMode || b.Mode == executableFileMode
+}
+
+// ObjectInfo holds the information 'git cat-file --batch-check' provides for an object
+type ObjectInfo struct {
+	SHA     string
+	Type    string
+	Size    int64
+	Missing bool
+}
+
+// StagedBlobs returns the blobs of all files added or modified by the staged changes
+func (r Repository) StagedBlobs() ([]ChangedBlob, error) {
+	out, err := r.CommandInput("", "diff", "--cached", "--raw", "-z", "--no-abbrev", "--no-renames", "--diff-filter=ACMT")
+	if err != nil {
+		return nil, err
+	}
+	return parseRawDiff(out), nil
+}
+
+// CommitBlobs returns the blobs of all files added or modified by the commit compared to its first parent
+func (r Repository) CommitBlobs(sha string) ([]ChangedBlob, error) {
+	out, err := r.CommandInput("", "diff-tree", "-r", "--raw", "-z", "--no-abbrev", "--no-renames", "--diff-filter=ACMT", "--root", "--no-commit-id", sha)
+	if err != nil {
+		return nil, err
+	}
+	return parseRawDiff(out), nil
+}
+
+// ObjectInfos returns type and size of the objects by passing them in one batch to 'git cat-file --batch-check'.
+// The infos are returned in the order of the objects.
+func (r Repository) ObjectInfos(objects ...string) ([]ObjectInfo, error) {
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	out, err := r.CommandInput(strings.Join(objects, "\n")+"\n", "cat-file", "--batch-check=%(objectname) %(objecttype) %(objectsize)")
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != len(objects) {
+		return nil, fmt.Errorf("expected %d objects from cat-file but got %d", len(objects), len(lines))
+	}
+	infos := make([]ObjectInfo, len(lines))
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == "missing" {
+			infos[i] = ObjectInfo{SHA: fields[0], Missing: true}
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected cat-file output '%s'", line)
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected object size in cat-file output '%s'", line)
+		}
+		infos[i] = ObjectInfo{SHA: fields[0], Type: fields[1], Size: size}
+	}
+	return infos, nil
+}
+
+// Attribute returns the value of a git attribute for every path as determined by 'git check-attr'. The attributes
+// are read from the index. Unspecified attributes are omitted.
+func (r Repository) Attribute(attr string, paths ...string) (map[string]string, error) {
+	values := map[string]string{}
+	if len(paths) == 0 {
+		return values, nil
+	}
+	out, err := r.CommandInput(strings.Join(paths, "\x00")+"\x00", "check-attr", "--cached", "-z", "--stdin", attr)
+	if err != nil {
+		return nil, err
+	}
+	// the output consists of NUL separated triples: path, attribute, value
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if fields[i+2] != "unspecified" {
+			values[fields[i]] = fields[i+2]
+		}
+	}
+	return values, nil
+}
+
+// parseRawDiff parses the output of 'git diff --raw -z'. Every entry consists of the metadata like
+// ':100644 100644 <old sha> <new sha> M' followed by the path. Gitlinks of submodules are omitted.
+func parseRawDiff(out string) []ChangedBlob {
+	var blobs []ChangedBlob
+	fields := strings.Split(out, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		meta := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(meta) < 5 || meta[1] == submoduleMode {
+			continue
+		}
+		blobs = append(blobs, ChangedBlob{Path: fields[i+1], SHA: meta[3], Mode: meta[1], Status: meta[4][:1]})
+	}
+	return blobs
+}
+
+// BlobContents returns the contents of the blobs by passing them in one batch to 'git cat-file --batch'. The
+// contents are returned in the order of the blobs.
+func (r Repository) BlobContents(shas ...string) ([]string, error) {
+	if len(shas) == 0 {
+		return nil, nil
+	}
+	out, err := r.CommandInput(strings.Join(shas, "\n")+"\n", "cat-file", "--batch")
+	if err != nil {
+		return nil, err
+	}
+	contents := make([]string, len(shas))
+	for i := range shas {
+		// every object is preceded by a header line '<sha> <type> <size>' and followed by a newline
+		end := strings.IndexByte(out, '\n')
+		if end < 0 {
+			return nil, fmt.Errorf("unexpected end of cat-file output")
+		}
+		header := strings.Fields(out[:end])
+		if len(header) != 3 {
+			return nil, fmt.Errorf("object '%s' is not available", shas[i])
+		}
+		size, err := strconv.Atoi(header[2])
+		if err != nil || end+1+size > len(out) {
+			return nil, fmt.Errorf("unexpected cat-file header '%s'", out[:end])
+		}
+		contents[i] = out[end+1 : end+1+size]
+		out = strings.TrimPrefix(out[end+1+size:], "\n")
+	}
+	return contents, nil
+}
+
+// WriteBlob stores the content as blob within the object database and returns its object name. No filters like
+// line ending conversions are applied to the content.
+func (r Repository) WriteBlob(content string) (string, error) {
+	out, err := r.CommandInput(content, "hash-object", "-w", "--no-filters", "--stdin")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// UpdateIndex points the index entry of the path to the blob without touching the working tree
+func (r Repository) UpdateIndex(mode string, sha string, path string) error {
+	_, err := r.Command("update-index", "--cacheinfo", fmt.Sprintf("%s,%s,%s", mode, sha, path))
+	return err
+}
+
+// IndexPaths returns the paths of all files within the index
+func (r Repository) IndexPaths() ([]string, error) {
+	out, err := r.CommandInput("", "ls-files", "-z", "--cached")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, p := range strings.Split(out, "\x00") {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,60 @@
+package git
+
+import (
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRepositoryBlobs(t *testing.T) {
+	tr := gittest.NewTestRepository(filepath.Join(t.TempDir(), "git-dir"))
+	r := NewRepository(tr.AbsDir())
+	tr.WriteFile(".gitattributes", "*.bin filter=lfs diff=lfs merge=lfs -text\n")
+	tr.WriteFile("a.txt", "hello")
+	tr.AddAll()
+	tr.Commit("initial")
+	tr.WriteFile("a.txt", "hello world")
+	tr.WriteFile("dir/with space.bin", strings.Repeat("x", 100))
+	tr.AddAll()
+
+	staged, err := r.StagedBlobs()
+	assert.NoError(t, err, "staged blobs should be determined")
+	assert.Len(t, staged, 2, "modified and added file should be staged")
+	assert.Equal(t, "a.txt", staged[0].Path, "modified file should be staged")
+	assert.Equal(t, "dir/with space.bin", staged[1].Path, "paths should not be quoted")
+	assert.Equal(t, []string{"M", "A"}, []string{staged[0].Status, staged[1].Status}, "status of the changes should be determined")
+
+	committed, err := r.CommitBlobs("HEAD")
+	assert.NoError(t, err, "blobs of the root commit should be determined")
+	assert.Len(t, committed, 2, "root commit should add all files")
+
+	infos, err := r.ObjectInfos(staged[0].SHA, staged[1].SHA, "0123456789012345678901234567890123456789")
+	assert.NoError(t, err, "object infos should be determined")
+	assert.Equal(t, []ObjectInfo{
+		{SHA: staged[0].SHA, Type: "blob", Size: 11},
+		{SHA: staged[1].SHA, Type: "blob", Size: 100},
+		{SHA: "0123456789012345678901234567890123456789", Missing: true},
+	}, infos, "object infos should be returned in order")
+
+	contents, err := r.BlobContents(staged[0].SHA, staged[1].SHA)
+	assert.NoError(t, err, "blob contents should be determined")
+	assert.Equal(t, []string{"hello world", strings.Repeat("x", 100)}, contents, "blob contents should be returned in order")
+	_, err = r.BlobContents("0123456789012345678901234567890123456789")
+	assert.Error(t, err, "missing blobs should result in an error")
+
+	sha, err := r.WriteBlob("rewritten\r\n")
+	assert.NoError(t, err, "blob should be written")
+	assert.NoError(t, r.UpdateIndex(staged[0].Mode, sha, "a.txt"), "index should be updated")
+	indexed, _ := tr.Command("show", ":a.txt")
+	assert.Equal(t, "rewritten\r\n", indexed, "index should point to the written blob")
+
+	paths, err := r.IndexPaths()
+	assert.NoError(t, err, "index paths should be determined")
+	assert.Equal(t, []string{".gitattributes", "a.txt", "dir/with space.bin"}, paths, "all indexed paths should be returned")
+
+	attrs, err := r.Attribute("filter", "a.txt", "dir/with space.bin")
+	assert.NoError(t, err, "attributes should be determined")
+	assert.Equal(t, map[string]string{"dir/with space.bin": "lfs"}, attrs, "only specified attributes should be returned")
+}
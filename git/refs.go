@@ -0,0 +1,221 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// hooksWithRefUpdates holds all hooks which receive their ref updates via stdin
+var hooksWithRefUpdates = []string{
+	HookPrePush,
+	HookPreReceive,
+	HookPostReceive,
+	HookPostRewrite,
+}
+
+// RefUpdate describes a single update which is passed via stdin to a hook. Since the hooks use different formats
+// the fields are normalized from the perspective of the repository the hook runs in:
+// - pre-push: the local ref is pushed onto the remote ref
+// - pre-receive / post-receive / update: the local ref and sha are the incoming ones, the remote sha is the current value
+// - post-rewrite: the local sha is the rewritten commit, the remote sha the original one; refs are empty
+type RefUpdate struct {
+	LocalRef  string
+	LocalSHA  string
+	RemoteRef string
+	RemoteSHA string
+	// NewBranch indicates that the remote ref does not exist yet
+	NewBranch bool
+	// Deletion indicates that the remote ref gets deleted
+	Deletion bool
+	// Force indicates that the update is not a fast-forward of the remote ref
+	Force bool
+	// Commits holds the commits introduced by the update
+	Commits []string
+}
+
+// HasRefUpdates returns whether the given hook receives ref updates via stdin
+func HasRefUpdates(hook string) bool {
+	for _, h := range hooksWithRefUpdates {
+		if h == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// IsZeroSHA returns whether the sha is the all-zero object name git uses for absent refs
+func IsZeroSHA(sha string) bool {
+	return sha != "" && strings.Trim(sha, "0") == ""
+}
+
+// ParseRefUpdates parses the stdin of a hook into ref updates. Flags which require the repository like force pushes
+// and the list of commits are not determined by the parsing, see ResolveRefUpdates.
+func ParseRefUpdates(hook string, input string) ([]RefUpdate, error) {
+	var updates []RefUpdate
+	for n, line := range strings.Split(input, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var u RefUpdate
+		switch hook {
+		case HookPrePush:
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("line %d of %s input is malformed: '%s'", n+1, hook, line)
+			}
+			u = RefUpdate{LocalRef: fields[0], LocalSHA: fields[1], RemoteRef: fields[2], RemoteSHA: fields[3]}
+		case HookPreReceive, HookPostReceive:
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("line %d of %s input is malformed: '%s'", n+1, hook, line)
+			}
+			u = RefUpdate{LocalRef: fields[2], LocalSHA: fields[1], RemoteRef: fields[2], RemoteSHA: fields[0]}
+		case HookPostRewrite:
+			// an additional field with extra information might be present
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d of %s input is malformed: '%s'", n+1, hook, line)
+			}
+			u = RefUpdate{LocalSHA: fields[1], RemoteSHA: fields[0]}
+		default:
+			return nil, fmt.Errorf("hook '%s' does not receive ref updates", hook)
+		}
+		if hook != HookPostRewrite {
+			u.NewBranch = IsZeroSHA(u.RemoteSHA)
+			u.Deletion = IsZeroSHA(u.LocalSHA)
+		}
+		updates = append(updates, u)
+	}
+	return updates, nil
+}
+
+// ParseUpdateArgs parses the arguments of the update hook, which are the ref name, the old and the new object name,
+// into a ref update. See ResolveRefUpdates for the flags which require the repository.
+func ParseUpdateArgs(args []string) (RefUpdate, error) {
+	if len(args) < 3 {
+		return RefUpdate{}, fmt.Errorf("hook '%s' requires the ref name, old and new object name but got '%s'", HookUpdate, strings.Join(args, " "))
+	}
+	return RefUpdate{
+		LocalRef:  args[0],
+		LocalSHA:  args[2],
+		RemoteRef: args[0],
+		RemoteSHA: args[1],
+		NewBranch: IsZeroSHA(args[1]),
+		Deletion:  IsZeroSHA(args[2]),
+	}, nil
+}
+
+// ResolveRefUpdates determines whether the updates are force pushes and which commits they introduce by inspecting
+// the repository located in dir.
+func ResolveRefUpdates(dir string, hook string, updates []RefUpdate) []RefUpdate {
+	// commits that are already known on the other side are excluded when ranges can not be determined directly
+	exclude := []string{"--remotes"}
+	switch hook {
+	case HookPreReceive, HookUpdate:
+		exclude = []string{"--all"}
+	case HookPostReceive:
+		exclude = postReceiveExcludes(updates)
+	}
+	for i, u := range updates {
+		switch {
+		case hook == HookPostRewrite:
+			u.Commits = []string{u.LocalSHA}
+		case u.Deletion:
+		case u.NewBranch:
+			u.Commits = revList(dir, append([]string{u.LocalSHA, "--not"}, exclude...)...)
+		default:
+			_, err := execGitCommand(dir, "merge-base", "--is-ancestor", u.RemoteSHA, u.LocalSHA)
+			// an unknown remote sha can not be an ancestor either
+			u.Force = err != nil
+			if u.Commits = revList(dir, u.RemoteSHA+".."+u.LocalSHA); u.Commits == nil {
+				u.Commits = revList(dir, append([]string{u.LocalSHA, "--not"}, exclude...)...)
+			}
+		}
+		updates[i] = u
+	}
+	return updates
+}
+
+// postReceiveExcludes returns the revisions which were known before the updates. Since the refs are already updated
+// when post-receive runs, all refs except the updated ones are excluded alongside the previous values of the updated
+// refs. HEAD is left out as well since it may point to an updated ref.
+func postReceiveExcludes(updates []RefUpdate) []string {
+	var exclude, previous []string
+	for _, u := range updates {
+		if u.RemoteRef != "" {
+			exclude = append(exclude, "--exclude="+u.RemoteRef)
+		}
+		if !u.NewBranch && !IsZeroSHA(u.RemoteSHA) {
+			previous = append(previous, u.RemoteSHA)
+		}
+	}
+	return append(append(exclude, "--glob=refs/*"), previous...)
+}
+
+// ApplyRefUpdates exposes the updates as variables. Every update is available via an indexed set of variables
+// (e.g. GIKS_PUSH_0_LOCAL_REF) whereas aggregated variables summarize all updates.
+func ApplyRefUpdates(updates []RefUpdate, vars map[string]string) {
+	var localRefs, remoteRefs []string
+	var newBranch, deletion, force bool
+	for i, u := range updates {
+		prefix := fmt.Sprintf("GIKS_PUSH_%d_", i)
+		vars[prefix+"LOCAL_REF"] = u.LocalRef
+		vars[prefix+"LOCAL_SHA"] = u.LocalSHA
+		vars[prefix+"REMOTE_REF"] = u.RemoteRef
+		vars[prefix+"REMOTE_SHA"] = u.RemoteSHA
+		vars[prefix+"NEW_BRANCH"] = strconv.FormatBool(u.NewBranch)
+		vars[prefix+"DELETION"] = strconv.FormatBool(u.Deletion)
+		vars[prefix+"FORCE"] = strconv.FormatBool(u.Force)
+		vars[prefix+"COMMITS"] = strings.Join(u.Commits, " ")
+		if u.LocalRef != "" {
+			localRefs = append(localRefs, u.LocalRef)
+		}
+		if u.RemoteRef != "" {
+			remoteRefs = append(remoteRefs, u.RemoteRef)
+		}
+		newBranch = newBranch || u.NewBranch
+		deletion = deletion || u.Deletion
+		force = force || u.Force
+	}
+	vars["GIKS_PUSH_COUNT"] = strconv.Itoa(len(updates))
+	vars["GIKS_PUSH_LOCAL_REFS"] = strings.Join(localRefs, " ")
+	vars["GIKS_PUSH_REMOTE_REFS"] = strings.Join(remoteRefs, " ")
+	vars["GIKS_PUSH_COMMITS"] = strings.Join(RefUpdateCommits(updates), " ")
+	vars["GIKS_PUSH_NEW_BRANCH"] = strconv.FormatBool(newBranch)
+	vars["GIKS_PUSH_DELETION"] = strconv.FormatBool(deletion)
+	vars["GIKS_PUSH_FORCE"] = strconv.FormatBool(force)
+}
+
+// RefUpdateCommits returns the commits introduced by all updates without duplicates
+func RefUpdateCommits(updates []RefUpdate) []string {
+	var commits []string
+	for _, u := range updates {
+		commits = AppendUnique(commits, u.Commits...)
+	}
+	return commits
+}
+
+// revList returns the commits listed by 'git rev-list' for the given arguments or nil in case of an error
+func revList(dir string, arg ...string) []string {
+	out, err := execGitCommand(dir, append([]string{"rev-list"}, arg...)...)
+	if err != nil {
+		return nil
+	}
+	if out == "" {
+		return []string{}
+	}
+	return strings.Split(out, "\n")
+}
+
+// AppendUnique appends the items which are not part of the list yet
+func AppendUnique(list []string, items ...string) []string {
+OUTER:
+	for _, item := range items {
+		for _, el := range list {
+			if el == item {
+				continue OUTER
+			}
+		}
+		list = append(list, item)
+	}
+	return list
+}
@@ -0,0 +1,162 @@
+package git
+
+import (
+	"github.com/jenpet/giks/test/gittest"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const zero = "0000000000000000000000000000000000000000"
+
+func TestParseRefUpdates(t *testing.T) {
+	parseTests := []struct {
+		name        string
+		hook        string
+		input       string
+		expected    []RefUpdate
+		errExpected bool
+	}{
+		{
+			"pre-push update",
+			HookPrePush,
+			"refs/heads/feat aaa refs/heads/main bbb\n",
+			[]RefUpdate{{LocalRef: "refs/heads/feat", LocalSHA: "aaa", RemoteRef: "refs/heads/main", RemoteSHA: "bbb"}},
+			false,
+		},
+		{
+			"pre-push new branch and deletion",
+			HookPrePush,
+			"refs/heads/feat aaa refs/heads/feat " + zero + "\n(delete) " + zero + " refs/heads/old bbb",
+			[]RefUpdate{
+				{LocalRef: "refs/heads/feat", LocalSHA: "aaa", RemoteRef: "refs/heads/feat", RemoteSHA: zero, NewBranch: true},
+				{LocalRef: "(delete)", LocalSHA: zero, RemoteRef: "refs/heads/old", RemoteSHA: "bbb", Deletion: true},
+			},
+			false,
+		},
+		{
+			"pre-receive update",
+			HookPreReceive,
+			"bbb aaa refs/heads/main",
+			[]RefUpdate{{LocalRef: "refs/heads/main", LocalSHA: "aaa", RemoteRef: "refs/heads/main", RemoteSHA: "bbb"}},
+			false,
+		},
+		{
+			"post-rewrite with extra information",
+			HookPostRewrite,
+			"bbb aaa extra",
+			[]RefUpdate{{LocalSHA: "aaa", RemoteSHA: "bbb"}},
+			false,
+		},
+		{
+			"empty input",
+			HookPrePush,
+			"\n",
+			nil,
+			false,
+		},
+		{
+			"malformed input",
+			HookPrePush,
+			"refs/heads/feat aaa",
+			nil,
+			true,
+		},
+		{
+			"unsupported hook",
+			HookPreCommit,
+			"a b c d",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range parseTests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates, err := ParseRefUpdates(tt.hook, tt.input)
+			assert.Equal(t, tt.errExpected, err != nil, "error expectation and result does not match")
+			assert.Equal(t, tt.expected, updates, "parsed updates do not match")
+		})
+	}
+}
+
+func TestParseUpdateArgs(t *testing.T) {
+	u, err := ParseUpdateArgs([]string{"refs/tags/v1", zero, "aaa"})
+	assert.NoError(t, err, "valid arguments should be parsed")
+	assert.Equal(t, RefUpdate{LocalRef: "refs/tags/v1", LocalSHA: "aaa", RemoteRef: "refs/tags/v1", RemoteSHA: zero, NewBranch: true}, u)
+	u, _ = ParseUpdateArgs([]string{"refs/heads/old", "bbb", zero})
+	assert.True(t, u.Deletion, "zero new object name should be a deletion")
+	_, err = ParseUpdateArgs([]string{"refs/heads/main"})
+	assert.Error(t, err, "missing object names should be rejected")
+}
+
+func TestResolveRefUpdates_shouldDetectCommitsAndForcePushes(t *testing.T) {
+	r := gittest.NewTestRepository(testGitDir)
+	defer r.Clean()
+	r.WriteFile("README", "first")
+	r.AddAll()
+	r.Commit("first")
+	first := revParse(r, "HEAD")
+	r.WriteFile("README", "second")
+	r.AddAll()
+	r.Commit("second")
+	second := revParse(r, "HEAD")
+	_, _ = r.Command("checkout", "-q", "-b", "rewritten", first)
+	r.WriteFile("README", "rewritten")
+	r.AddAll()
+	r.Commit("rewritten")
+	rewritten := revParse(r, "HEAD")
+
+	updates := ResolveRefUpdates(r.AbsDir(), HookPrePush, []RefUpdate{
+		{LocalRef: "refs/heads/main", LocalSHA: second, RemoteRef: "refs/heads/main", RemoteSHA: first},
+		{LocalRef: "refs/heads/rewritten", LocalSHA: rewritten, RemoteRef: "refs/heads/main", RemoteSHA: second},
+		{LocalRef: "refs/heads/new", LocalSHA: second, RemoteRef: "refs/heads/new", RemoteSHA: zero, NewBranch: true},
+	})
+	assert.False(t, updates[0].Force, "fast-forward should not be a force push")
+	assert.Equal(t, []string{second}, updates[0].Commits, "only the new commit should be pushed")
+	assert.True(t, updates[1].Force, "non fast-forward should be a force push")
+	assert.Equal(t, []string{rewritten}, updates[1].Commits, "only the rewritten commit should be pushed")
+	assert.Equal(t, []string{second, first}, updates[2].Commits, "all commits unknown to remotes should be pushed")
+
+	vars := map[string]string{}
+	ApplyRefUpdates(updates, vars)
+	assert.Equal(t, "3", vars["GIKS_PUSH_COUNT"])
+	assert.Equal(t, "true", vars["GIKS_PUSH_1_FORCE"])
+	assert.Equal(t, "true", vars["GIKS_PUSH_2_NEW_BRANCH"])
+	assert.Equal(t, "true", vars["GIKS_PUSH_FORCE"])
+	assert.Equal(t, "refs/heads/main refs/heads/main refs/heads/new", vars["GIKS_PUSH_REMOTE_REFS"])
+	assert.ElementsMatch(t, []string{first, second, rewritten}, strings.Split(vars["GIKS_PUSH_COMMITS"], " "))
+}
+
+func TestResolveRefUpdates_postReceiveShouldIgnoreUpdatedRefs(t *testing.T) {
+	r := gittest.NewTestRepository(testGitDir)
+	defer r.Clean()
+	r.WriteFile("README", "first")
+	r.AddAll()
+	r.Commit("first")
+	first := revParse(r, "HEAD")
+	// the refs are already updated when post-receive runs
+	_, _ = r.Command("checkout", "-q", "-b", "feature")
+	r.WriteFile("README", "second")
+	r.AddAll()
+	r.Commit("second")
+	second := revParse(r, "HEAD")
+	_, _ = r.Command("branch", "copy")
+
+	updates := ResolveRefUpdates(r.AbsDir(), HookPostReceive, []RefUpdate{
+		{LocalRef: "refs/heads/feature", LocalSHA: second, RemoteRef: "refs/heads/feature", RemoteSHA: zero, NewBranch: true},
+		{LocalRef: "refs/heads/copy", LocalSHA: second, RemoteRef: "refs/heads/copy", RemoteSHA: zero, NewBranch: true},
+	})
+	assert.Equal(t, []string{second}, updates[0].Commits, "commits of the new branch should be determined")
+	assert.Equal(t, []string{second}, updates[1].Commits, "commits of other updated refs should not be excluded")
+	assert.NotContains(t, updates[0].Commits, first, "commits of refs which were not updated should be excluded")
+}
+
+func TestRefUpdateCommits(t *testing.T) {
+	updates := []RefUpdate{{Commits: []string{"b", "a"}}, {Commits: []string{"c", "b"}}, {Deletion: true}}
+	assert.Equal(t, []string{"b", "a", "c"}, RefUpdateCommits(updates), "commits should be returned once")
+}
+
+func revParse(r gittest.TestRepository, rev string) string {
+	out, _ := r.Command("rev-parse", rev)
+	return strings.TrimSpace(out)
+}
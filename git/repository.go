@@ -0,0 +1,28 @@
+package git
+
+// Repository is a handle for a git repository which is used to execute git commands within it
+type Repository struct {
+	// Dir is the directory the git commands are executed in, usually the root of the repository
+	Dir string
+}
+
+// NewRepository returns a handle for the repository located in dir
+func NewRepository(dir string) Repository {
+	return Repository{Dir: dir}
+}
+
+// Command executes a git command within the repository and returns its trimmed output
+func (r Repository) Command(arg ...string) (string, error) {
+	return execGitCommand(r.Dir, arg...)
+}
+
+// CommandInput executes a git command within the repository which reads the input from stdin and returns its
+// untrimmed output
+func (r Repository) CommandInput(input string, arg ...string) (string, error) {
+	return execGitCommandInput(r.Dir, input, arg...)
+}
+
+// CurrentBranch returns the short name of the checked out branch. An error is returned in case HEAD is detached.
+func (r Repository) CurrentBranch() (string, error) {
+	return r.Command("symbolic-ref", "--short", "-q", "HEAD")
+}
@@ -2,7 +2,9 @@ package log
 
 import (
 	"github.com/sirupsen/logrus"
+	"io"
 	"os"
+	"strings"
 )
 
 var logger = logrus.New()
@@ -55,3 +57,17 @@ func Errorf(format string, args ...interface{}) {
 	logger.Errorf(format, args...)
 	os.Exit(1)
 }
+
+// Writer returns a writer which logs every written line at level Info on the standard logger.
+func Writer() io.Writer {
+	return lineWriter{}
+}
+
+type lineWriter struct{}
+
+func (lw lineWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		logger.Info(line)
+	}
+	return len(p), nil
+}
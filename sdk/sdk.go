@@ -0,0 +1,114 @@
+// Package sdk is the public API for writing giks plugins in Go. Plugins are compiled into a custom giks binary by
+// registering them from an init function and calling Main from the binary's main function:
+//
+//	package main
+//
+//	import (
+//		"github.com/jenpet/giks/sdk"
+//		_ "example.com/org/giksplugins" // calls sdk.Register within its init function
+//	)
+//
+//	func main() { sdk.Main() }
+//
+// Registered plugins can be used like any built-in plugin via 'plugin: name:' within the giks configuration.
+package sdk
+
+import (
+	"github.com/jenpet/giks/args"
+	"github.com/jenpet/giks/commands"
+	"github.com/jenpet/giks/commands/plugins"
+	"github.com/jenpet/giks/config"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/log"
+	"os"
+)
+
+// Plugin has to be implemented by every plugin, see plugins.Plugin
+type Plugin = plugins.Plugin
+
+// Describer can optionally be implemented by plugins in order to document themselves, see plugins.Describer
+type Describer = plugins.Describer
+
+// RunInput holds everything a plugin run can rely on, see plugins.RunInput
+type RunInput = plugins.RunInput
+
+// Result is returned by every plugin run, see plugins.Result
+type Result = plugins.Result
+
+// Finding points at a specific location which caused a plugin to warn or fail, see plugins.Finding
+type Finding = plugins.Finding
+
+// Status describes the outcome of a plugin run, see plugins.Status
+type Status = plugins.Status
+
+// Vars holds the variables of a plugin run, see plugins.Vars
+type Vars = plugins.Vars
+
+// VarSpec describes a single variable of a plugin, see plugins.VarSpec
+type VarSpec = plugins.VarSpec
+
+// Repository is the handle of the git repository a plugin runs for, see git.Repository
+type Repository = git.Repository
+
+const (
+	StatusPass  = plugins.StatusPass
+	StatusWarn  = plugins.StatusWarn
+	StatusFail  = plugins.StatusFail
+	StatusSkip  = plugins.StatusSkip
+	StatusError = plugins.StatusError
+)
+
+const (
+	VarTypeString = plugins.VarTypeString
+	VarTypeBool   = plugins.VarTypeBool
+	VarTypeInt    = plugins.VarTypeInt
+	VarTypeFloat  = plugins.VarTypeFloat
+	VarTypeRegexp = plugins.VarTypeRegexp
+	VarTypeList   = plugins.VarTypeList
+)
+
+// Register makes a plugin available under its ID. It is meant to be called from an init function and panics in case
+// the ID is empty or already registered.
+func Register(p Plugin) {
+	plugins.Register(p)
+}
+
+// MergeVars merges configured plugin variables into the variables provided by giks, see plugins.MergeVars
+func MergeVars(vars map[string]string, pluginVars map[string]string) Vars {
+	return plugins.MergeVars(vars, pluginVars)
+}
+
+// Pass returns a passing result
+func Pass() Result {
+	return plugins.Pass()
+}
+
+// Skip returns a result indicating that the plugin did not check anything
+func Skip(format string, args ...interface{}) Result {
+	return plugins.Skip(format, args...)
+}
+
+// Errorf returns a result indicating that the plugin could not be executed properly
+func Errorf(format string, args ...interface{}) Result {
+	return plugins.Errorf(format, args...)
+}
+
+// Fail returns a failing result in case blocking is set, otherwise a warning
+func Fail(blocking bool, msg string, findings ...Finding) Result {
+	return plugins.Fail(blocking, msg, findings...)
+}
+
+// Main runs giks with the arguments of the current process including all registered plugins
+func Main() {
+	// parse into specific giks arguments to ease command, subcommand and argument handling
+	var ga args.GiksArgs = os.Args
+	// initialize the logger in case debug logging is required
+	log.Init(ga.Debug())
+	// recursive commands assemble a configuration per repository
+	if dir, ok := ga.Recursive(); ok {
+		commands.ProcessRecursive(ga, dir)
+		return
+	}
+	cfg := config.AssembleConfig(ga)
+	commands.Process(cfg, ga)
+}
@@ -0,0 +1,54 @@
+package sdk_test
+
+import (
+	"context"
+	"github.com/jenpet/giks/commands/plugins"
+	"github.com/jenpet/giks/sdk"
+	"github.com/jenpet/giks/sdk/sdktest"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// todoFinder fails in case a staged file contains 'TODO'
+type todoFinder struct{}
+
+func (tf todoFinder) ID() string {
+	return "todo-finder"
+}
+
+func (tf todoFinder) Run(ctx context.Context, in sdk.RunInput) sdk.Result {
+	var findings []sdk.Finding
+	for _, file := range in.Vars.List("GIKS_MIXIN_STAGED_FILES") {
+		content, _ := in.Repo.Command("show", ":"+file)
+		if strings.Contains(content, "TODO") {
+			findings = append(findings, sdk.Finding{File: file, Message: "contains a TODO"})
+		}
+	}
+	if len(findings) > 0 {
+		return sdk.Fail(true, "staged files contain TODOs", findings...)
+	}
+	return sdk.Pass()
+}
+
+// plugins are registered once per process just like custom plugins of a giks build do
+func init() {
+	sdk.Register(todoFinder{})
+}
+
+func TestRegister_shouldMakePluginAvailable(t *testing.T) {
+	p, err := plugins.Get("todo-finder")
+	assert.NoError(t, err, "registered plugin should be available")
+	assert.Equal(t, todoFinder{}, p, "registered plugin should be returned")
+	assert.Panics(t, func() { sdk.Register(todoFinder{}) }, "registering a plugin twice should panic")
+
+	r := sdktest.NewRepository(t)
+	r.Stage("main.go", "package main // TODO")
+	res := sdktest.Run(t, p, r, sdktest.Options{Hook: "pre-commit"})
+	assert.Equal(t, sdk.StatusFail, res.Status, "plugin should fail for staged TODOs")
+	assert.Equal(t, "staged files contain TODOs\nmain.go: contains a TODO", res.String())
+
+	r.Stage("main.go", "package main")
+	res = sdktest.Run(t, p, r, sdktest.Options{Hook: "pre-commit"})
+	assert.Equal(t, sdk.StatusPass, res.Status, "plugin should pass without staged TODOs")
+}
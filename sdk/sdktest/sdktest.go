@@ -0,0 +1,85 @@
+// Package sdktest provides helpers to test plugins written with the giks sdk against temporary git repositories.
+package sdktest
+
+import (
+	"context"
+	"github.com/jenpet/giks/git"
+	"github.com/jenpet/giks/sdk"
+	"github.com/jenpet/giks/test/gittest"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Repository is a temporary git repository which gets removed once the test finished
+type Repository struct {
+	gittest.TestRepository
+}
+
+// NewRepository initializes a new temporary git repository for the test
+func NewRepository(t testing.TB) Repository {
+	t.Helper()
+	return Repository{gittest.NewTestRepository(filepath.Join(t.TempDir(), "repo"))}
+}
+
+// Stage writes the file and adds it to the index
+func (r Repository) Stage(filename, content string) {
+	r.WriteFile(filename, content)
+	_, _ = r.Command("add", "--", filename)
+}
+
+// Rev returns the object name of the given revision or an empty string in case it can not be resolved
+func (r Repository) Rev(rev string) string {
+	out, err := r.Command("rev-parse", rev)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// Options configure a single plugin run
+type Options struct {
+	Hook string
+	Args []string
+	// Vars are the configured plugin variables which get merged with the variables giks provides
+	Vars map[string]string
+	// Stdin is passed as the hook input. Ref updates of hooks like pre-push are parsed from it, the update hook takes
+	// its ref update from Args instead.
+	Stdin string
+	// Output receives the messages of the plugin. It defaults to discarding all messages.
+	Output io.Writer
+}
+
+// Run executes the plugin within the repository the same way giks does during a hook execution
+func Run(t testing.TB, p sdk.Plugin, r Repository, opts Options) sdk.Result {
+	t.Helper()
+	vars := map[string]string{}
+	git.ApplyMixins(r.AbsDir(), vars)
+	vars["GIKS_HOOK_TYPE"] = opts.Hook
+	if opts.Hook == git.HookUpdate {
+		u, err := git.ParseUpdateArgs(opts.Args)
+		if err != nil {
+			t.Fatalf("could not parse ref update: %+v", err)
+		}
+		git.ApplyRefUpdates(git.ResolveRefUpdates(r.AbsDir(), opts.Hook, []git.RefUpdate{u}), vars)
+	} else if git.HasRefUpdates(opts.Hook) {
+		updates, err := git.ParseRefUpdates(opts.Hook, opts.Stdin)
+		if err != nil {
+			t.Fatalf("could not parse ref updates: %+v", err)
+		}
+		git.ApplyRefUpdates(git.ResolveRefUpdates(r.AbsDir(), opts.Hook, updates), vars)
+	}
+	out := opts.Output
+	if out == nil {
+		out = io.Discard
+	}
+	return p.Run(context.Background(), sdk.RunInput{
+		Hook:   opts.Hook,
+		Args:   opts.Args,
+		Vars:   sdk.MergeVars(vars, opts.Vars),
+		Repo:   git.NewRepository(r.AbsDir()),
+		Stdin:  strings.NewReader(opts.Stdin),
+		Output: out,
+	})
+}
@@ -25,6 +25,9 @@ func (tr TestRepository) init() {
 	if err := cmd.Run(); err != nil {
 		panic("could not initiate test git repo. Error: " + err.Error())
 	}
+	// commits require an identity which might not be configured globally
+	_, _ = tr.Command("config", "user.name", "giks")
+	_, _ = tr.Command("config", "user.email", "giks@example.com")
 }
 
 func (tr TestRepository) Command(arg ...string) (string, error) {
@@ -38,7 +41,9 @@ func (tr TestRepository) Command(arg ...string) (string, error) {
 }
 
 func (tr TestRepository) WriteFile(filename, content string) {
-	_ = os.WriteFile(filepath.Join(tr.dir, filename), []byte(content), 0777)
+	file := filepath.Join(tr.dir, filename)
+	_ = os.MkdirAll(filepath.Dir(file), 0777)
+	_ = os.WriteFile(file, []byte(content), 0777)
 }
 
 func (tr TestRepository) AddAll() {